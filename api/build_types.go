@@ -17,6 +17,7 @@
 package api
 
 import (
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -43,6 +44,21 @@ const (
 	BuildStrategyPod BuildStrategy = "pod"
 )
 
+// SecurityProfile specifies the set of security constraints applied to the builder `Pod` and its containers.
+// Profiles follow the Kubernetes Pod Security Standards (https://kubernetes.io/docs/concepts/security/pod-security-standards/).
+// +kubebuilder:validation:Enum=privileged;baseline;restricted
+type SecurityProfile string
+
+const (
+	// SecurityProfilePrivileged runs the builder container in privileged mode. Use it only when the builder can't work otherwise.
+	SecurityProfilePrivileged SecurityProfile = "privileged"
+	// SecurityProfileBaseline prevents known privilege escalations while still allowing the builder to run as root.
+	// This is the default profile.
+	SecurityProfileBaseline SecurityProfile = "baseline"
+	// SecurityProfileRestricted enforces the current Pod hardening best practices: non-root user, no capabilities and no privilege escalation.
+	SecurityProfileRestricted SecurityProfile = "restricted"
+)
+
 // BuildSpec defines the Build operation to be executed
 type BuildSpec struct {
 	// The sequence of Build tasks to be performed as part of the Build execution.
@@ -55,6 +71,40 @@ type BuildSpec struct {
 	// and its phase set to BuildPhaseFailed.
 	// +kubebuilder:validation:Format=duration
 	Timeout metav1.Duration `json:"timeout,omitempty"`
+	// SecurityProfile the security constraints applied to the Build execution. Defaults to `baseline`.
+	SecurityProfile SecurityProfile `json:"securityProfile,omitempty"`
+	// Job when set, the builder `Pod` is executed by a Kubernetes `Job` configured accordingly.
+	// +optional
+	Job *BuildJobSpec `json:"job,omitempty"`
+	// InputCache when true, the build is skipped if the registry already holds an image built from the same inputs.
+	// See BuildStatus.InputHash. Only applied by Scheduler.Schedule, the Build Custom Resources don't support it.
+	// +optional
+	InputCache bool `json:"inputCache,omitempty"`
+	// PinBaseImages when true, the base images are resolved to their current digest before building,
+	// so that the build is not affected by their tags moving. See BuildStatus.BaseImage.
+	// Only applied by Scheduler.Schedule, the Build Custom Resources don't support it.
+	// +optional
+	PinBaseImages bool `json:"pinBaseImages,omitempty"`
+}
+
+// BuildJobSpec configures the Kubernetes `Job` wrapping the builder `Pod`.
+// A Job survives node failures and can be garbage collected once finished.
+type BuildJobSpec struct {
+	// Specifies the number of retries before marking the Job as failed.
+	// Defaults to 0, since the build failures are already recovered by the builder.
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+	// Specifies the duration in seconds relative to the start time that the Job may be active before the system tries to terminate it.
+	// Defaults to the Build Timeout.
+	// +optional
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+	// Limits the lifetime of the Job after it finishes execution (either Complete or Failed).
+	// Make sure the Build is reconciled before the Job is deleted, otherwise the Build is interrupted.
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+	// Specifies the policy of handling failed pods, see https://kubernetes.io/docs/concepts/workloads/controllers/job/#pod-failure-policy.
+	// +optional
+	PodFailurePolicy *batchv1.PodFailurePolicy `json:"podFailurePolicy,omitempty"`
 }
 
 // RegistrySpec provides the configuration for the container registry
@@ -69,6 +119,24 @@ type RegistrySpec struct {
 	CA string `json:"ca,omitempty"`
 	// the registry organization
 	Organization string `json:"organization,omitempty"`
+	// the mirrors of the registries the images are pulled from, like pull-through caches
+	Mirrors []RegistryMirror `json:"mirrors,omitempty"`
+}
+
+// RegistryMirror the mirrors of a registry, tried in order before the registry itself
+type RegistryMirror struct {
+	// the mirrored registry, like "docker.io" or "quay.io"
+	Registry string `json:"registry"`
+	// the mirrors of the registry, in the order they're tried
+	Endpoints []MirrorEndpoint `json:"endpoints"`
+}
+
+// MirrorEndpoint a mirror of a registry
+type MirrorEndpoint struct {
+	// the mirror host, with an optional port and path, like "mirror.example.com:5000/docker-hub"
+	Location string `json:"location"`
+	// if the mirror is insecure (ie, http only)
+	Insecure bool `json:"insecure,omitempty"`
 }
 
 // Task represents the abstract task. Only one of the task should be configured to represent the specific task chosen.
@@ -107,6 +175,76 @@ type KanikoTask struct {
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
 	// AdditionalFlags -- List of additional flags for  the Kaniko process (see https://github.com/GoogleContainerTools/kaniko/blob/main/README.md#additional-flags)
 	AdditionalFlags []string `json:"additionalFlags,omitempty"`
+	// BuildArgs -- the values of the Dockerfile ARG instructions. They are recorded in the image history like with any builder,
+	// use BuildSecrets for the credentials required while building.
+	BuildArgs []BuildArg `json:"buildArgs,omitempty"`
+	// BuildSecrets -- the Secrets mounted in the builder for the Dockerfile to read their files, they are not part of the built image
+	BuildSecrets []BuildSecret `json:"buildSecrets,omitempty"`
+	// Options -- the Kaniko options, checked against the version of the Kaniko executor unlike the AdditionalFlags
+	Options KanikoOptions `json:"options,omitempty"`
+	// ExecutorImage -- the image of the Kaniko executor, else the one of the KANIKO_EXECUTOR_IMAGE environment variable, else the default one
+	ExecutorImage string `json:"executorImage,omitempty"`
+}
+
+// KanikoSnapshotMode how Kaniko detects the files changed by every Dockerfile instruction
+type KanikoSnapshotMode string
+
+const (
+	// KanikoSnapshotModeFull compares the content and the metadata of the files
+	KanikoSnapshotModeFull KanikoSnapshotMode = "full"
+	// KanikoSnapshotModeRedo compares the metadata of the files, faster than full with less memory than time
+	KanikoSnapshotModeRedo KanikoSnapshotMode = "redo"
+	// KanikoSnapshotModeTime compares the modification time of the files only
+	KanikoSnapshotModeTime KanikoSnapshotMode = "time"
+)
+
+// KanikoOptions the common options of the Kaniko executor, unset options keep the executor defaults
+// (see https://github.com/GoogleContainerTools/kaniko/blob/main/README.md#additional-flags)
+type KanikoOptions struct {
+	// how the changed files are detected, one of full, redo or time
+	SnapshotMode KanikoSnapshotMode `json:"snapshotMode,omitempty"`
+	// run the RUN instructions detecting the changed files with their modification time, faster with large file systems
+	UseNewRun *bool `json:"useNewRun,omitempty"`
+	// compress the cached layers, trading memory for CPU
+	CompressedCaching *bool `json:"compressedCaching,omitempty"`
+	// strip the timestamps from the image, so that the same inputs give the same image digest
+	Reproducible *bool `json:"reproducible,omitempty"`
+	// the number of retries of the image push
+	PushRetry *int32 `json:"pushRetry,omitempty"`
+	// the stage of a multi-stage Dockerfile to build
+	Target string `json:"target,omitempty"`
+	// take a single snapshot of the file system at the end of the build
+	SingleSnapshot *bool `json:"singleSnapshot,omitempty"`
+	// clean the file system at the end of the build
+	Cleanup *bool `json:"cleanup,omitempty"`
+}
+
+// BuildArg the value of a Dockerfile ARG instruction, given literally or read from a ConfigMap or a Secret.
+// The values read from a Secret are never written in the builder Pod, the builder reads them from its environment.
+type BuildArg struct {
+	// the name of the argument
+	Name string `json:"name"`
+	// the literal value of the argument
+	Value string `json:"value,omitempty"`
+	// the source of the argument value, instead of a literal value. The name must not be one of the builder environment, see IsReservedEnvName
+	ValueFrom *BuildArgSource `json:"valueFrom,omitempty"`
+}
+
+// BuildArgSource the ConfigMap or the Secret key holding the value of a BuildArg, only one must be set
+type BuildArgSource struct {
+	// the key of a ConfigMap in the build namespace
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// the key of a Secret in the build namespace
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// BuildSecret a Secret of the build namespace mounted in the builder, every key being a file the Dockerfile can read,
+// like `RUN --mount` would do with other builders. The files are not part of the built image unless the Dockerfile copies them.
+type BuildSecret struct {
+	// the name of the Secret
+	Name string `json:"name"`
+	// the directory of the Secret files, defaults to /kaniko/secrets/<name>
+	MountPath string `json:"mountPath,omitempty"`
 }
 
 // KanikoTaskCache is used to configure Kaniko cache
@@ -136,11 +274,23 @@ const (
 	// BuildPhaseFailed --
 	BuildPhaseFailed BuildPhase = "Failed"
 	// BuildPhaseInterrupted --
-	BuildPhaseInterrupted = "Interrupted"
+	BuildPhaseInterrupted BuildPhase = "Interrupted"
 	// BuildPhaseError --
 	BuildPhaseError BuildPhase = "Error"
 )
 
+// BuildPhaseTransition records a change of the Build phase
+type BuildPhaseTransition struct {
+	// the phase the Build moved from
+	From BuildPhase `json:"from,omitempty"`
+	// the phase the Build moved to
+	To BuildPhase `json:"to"`
+	// the time when the transition occurred
+	Time metav1.Time `json:"time"`
+	// what caused the transition, usually the name of the builder action
+	Reason string `json:"reason,omitempty"`
+}
+
 // BuildConditionType --
 type BuildConditionType string
 
@@ -170,8 +320,13 @@ type BuildStatus struct {
 	Image string `json:"image,omitempty"`
 	// the digest from image
 	Digest string `json:"digest,omitempty"`
-	// the base image used for this build
+	// the base image used for this build, pinned to its digest like "quay.io/kiegroup/kogito-swf-builder:latest@sha256:..."
+	// when PinBaseImages is set
 	BaseImage string `json:"baseImage,omitempty"`
+	// every base image pinned to its digest, including BaseImage and the images of the previous Dockerfile stages
+	PinnedBaseImages []string `json:"pinnedBaseImages,omitempty"`
+	// the Kaniko version of the executor image, found from its tag or its labels, which the supported options depend on
+	ExecutorVersion string `json:"executorVersion,omitempty"`
 	// the error description (if any)
 	Error string `json:"error,omitempty"`
 	// the reason of the failure (if any)
@@ -180,14 +335,64 @@ type BuildStatus struct {
 	StartedAt *metav1.Time `json:"startedAt,omitempty"`
 	// a list of conditions occurred during the build
 	Conditions []BuildCondition `json:"conditions,omitempty"`
-	// how long it took for the build
-	// Change to Duration / ISO 8601 when CRD uses OpenAPI spec v3
-	// https://github.com/OAI/OpenAPI-Specification/issues/845
+	// the hash of the build inputs, like the resources and the base images digests, when the input cache is enabled.
+	// The built image is labelled and tagged with it.
+	InputHash string `json:"inputHash,omitempty"`
+	// the history of the phase transitions, oldest first
+	Transitions []BuildPhaseTransition `json:"transitions,omitempty"`
+	// the ID of the trace linking the spans of the build actions, when tracing is enabled
+	TraceID string `json:"traceID,omitempty"`
+	// the ID of the first span of the build, parent of the spans of the following actions
+	SpanID string `json:"spanID,omitempty"`
+	// how long it took for the build, see Timings for the time spent in every step
 	Duration string `json:"duration,omitempty"`
+	// the timestamps of the build steps and the durations computed from them
+	Timings *BuildTimings `json:"timings,omitempty"`
+	// the progress of the builder, while the build is running
+	Progress *BuildProgress `json:"progress,omitempty"`
 	// reference to where the build resources are located
 	ResourceVolume *ResourceVolume `json:"resourceVolume,omitempty"`
 }
 
+// BuildTimings the timestamps of the steps of the last build attempt, and the time spent between them.
+// The timestamps are unknown until the step occurs, the push is known only when the builder output tells it.
+type BuildTimings struct {
+	// the time when the build was created
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+	// the time when the build was queued, waiting for its builder
+	QueuedAt *metav1.Time `json:"queuedAt,omitempty"`
+	// the time when the builder Pod was scheduled on a node
+	ScheduledAt *metav1.Time `json:"scheduledAt,omitempty"`
+	// the time when the builder container started, after pulling its image and running the init containers
+	ContainerStartedAt *metav1.Time `json:"containerStartedAt,omitempty"`
+	// the time when the builder started to push the image
+	PushStartedAt *metav1.Time `json:"pushStartedAt,omitempty"`
+	// the time when the builder finished
+	FinishedAt *metav1.Time `json:"finishedAt,omitempty"`
+	// the time spent waiting for the builder Pod to be scheduled
+	QueueDuration *metav1.Duration `json:"queueDuration,omitempty"`
+	// the time spent to start the builder container, mostly pulling its image
+	PullDuration *metav1.Duration `json:"pullDuration,omitempty"`
+	// the time spent building the image, until the push or the end of the builder when the push is unknown
+	BuildDuration *metav1.Duration `json:"buildDuration,omitempty"`
+	// the time spent pushing the image
+	PushDuration *metav1.Duration `json:"pushDuration,omitempty"`
+}
+
+// BuildProgress the progress of the builder, parsed from its output
+type BuildProgress struct {
+	// the current step, like the Dockerfile instruction being executed or the image push
+	Step string `json:"step,omitempty"`
+	// the number of the Dockerfile instructions executed so far, including the current one
+	StepNumber int `json:"stepNumber,omitempty"`
+	// the number of instructions in the Dockerfile, unknown when the Dockerfile is not a build resource
+	TotalSteps int `json:"totalSteps,omitempty"`
+	// the time of the last output of the builder
+	LastActivityAt *metav1.Time `json:"lastActivityAt,omitempty"`
+	// the number of output lines of the builder logged in the second of LastActivityAt, so that its output is read incrementally
+	LastActivityLines int `json:"lastActivityLines,omitempty"`
+}
+
 // Failure represent a message specifying the reason and the time of an event failure
 type Failure struct {
 	// a short text specifying the reason
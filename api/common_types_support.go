@@ -20,6 +20,8 @@ import (
 	"strconv"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiegroup/container-builder/util/defaults"
 )
 
 // IsOptionEnabled return whether if the PublishStrategyOptions is enabled or not
@@ -38,7 +40,90 @@ func (b PlatformBuildSpec) IsOptionEnabled(option string) bool {
 // GetTimeout returns the specified duration or a default one
 func (b PlatformBuildSpec) GetTimeout() metav1.Duration {
 	if b.Timeout == nil {
-		return metav1.Duration{}
+		return metav1.Duration{Duration: defaults.BuildTimeout}
 	}
 	return *b.Timeout
 }
+
+// GetExecutorImage returns the specified Kaniko executor image, else the one of the environment, else the default one
+func (b PlatformBuildSpec) GetExecutorImage() string {
+	return defaults.GetKanikoExecutorImage(b.ExecutorImage)
+}
+
+// GetExecutorImage returns the specified Kaniko executor image, else the one of the environment, else the default one
+func (t KanikoTask) GetExecutorImage() string {
+	return defaults.GetKanikoExecutorImage(t.ExecutorImage)
+}
+
+// GetSecurityProfile returns the specified security profile or the default one
+func (b PlatformBuildSpec) GetSecurityProfile() SecurityProfile {
+	if b.SecurityProfile == "" {
+		return SecurityProfileBaseline
+	}
+	return b.SecurityProfile
+}
+
+// GetSecurityProfile returns the specified security profile or the default one
+func (b BuildSpec) GetSecurityProfile() SecurityProfile {
+	if b.SecurityProfile == "" {
+		return SecurityProfileBaseline
+	}
+	return b.SecurityProfile
+}
+
+// buildPhaseTransitions the phases a Build can move to from every phase. Finished phases can't change anymore.
+// Any phase which is not finished can move to Error or Interrupted, for example when the build is cancelled.
+// A scheduled build succeeds right away when the input cache finds the image of a build with the same inputs.
+var buildPhaseTransitions = map[BuildPhase][]BuildPhase{
+	BuildPhaseNone:           {BuildPhaseInitialization, BuildPhaseScheduling},
+	BuildPhaseInitialization: {BuildPhaseScheduling},
+	BuildPhaseScheduling:     {BuildPhasePending, BuildPhaseSucceeded},
+	BuildPhasePending:        {BuildPhaseRunning, BuildPhaseSucceeded, BuildPhaseFailed},
+	BuildPhaseRunning:        {BuildPhaseSucceeded, BuildPhaseFailed},
+	BuildPhaseFailed:         {BuildPhaseInitialization},
+}
+
+// CanTransitionTo returns true if a Build in this phase can move to the given one. Staying in the same phase is always allowed.
+func (p BuildPhase) CanTransitionTo(next BuildPhase) bool {
+	if p == next {
+		return true
+	}
+	if p.IsFinished() {
+		return false
+	}
+	if next == BuildPhaseError || next == BuildPhaseInterrupted {
+		return true
+	}
+	for _, phase := range buildPhaseTransitions[p] {
+		if phase == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinished returns true if the Build reached a phase that won't change anymore
+func (p BuildPhase) IsFinished() bool {
+	return p == BuildPhaseSucceeded || p == BuildPhaseError || p == BuildPhaseInterrupted
+}
+
+// SetDurations computes the durations between the timestamps known so far.
+// The build lasts until the push starts, or until the builder finishes when the push is unknown.
+func (t *BuildTimings) SetDurations() {
+	t.QueueDuration = durationBetween(t.QueuedAt, t.ScheduledAt)
+	t.PullDuration = durationBetween(t.ScheduledAt, t.ContainerStartedAt)
+	if t.PushStartedAt != nil {
+		t.BuildDuration = durationBetween(t.ContainerStartedAt, t.PushStartedAt)
+		t.PushDuration = durationBetween(t.PushStartedAt, t.FinishedAt)
+	} else {
+		t.BuildDuration = durationBetween(t.ContainerStartedAt, t.FinishedAt)
+		t.PushDuration = nil
+	}
+}
+
+func durationBetween(from, to *metav1.Time) *metav1.Duration {
+	if from == nil || to == nil || to.Before(from) {
+		return nil
+	}
+	return &metav1.Duration{Duration: to.Sub(from.Time)}
+}
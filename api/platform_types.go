@@ -35,6 +35,17 @@ type PlatformBuildSpec struct {
 	Registry RegistrySpec `json:"registry,omitempty"`
 	// how much time to wait before time out the build process
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// the security constraints applied to the builder Pod. Defaults to `baseline`.
+	SecurityProfile SecurityProfile `json:"securityProfile,omitempty"`
+	// when set, builds are executed by a Kubernetes Job instead of a bare Pod
+	Job *BuildJobSpec `json:"job,omitempty"`
+	// when true, the builds are skipped if the registry already holds an image built from the same inputs
+	InputCache bool `json:"inputCache,omitempty"`
+	// when true, the base images are pinned to their current digest before building
+	PinBaseImages bool `json:"pinBaseImages,omitempty"`
+	// the image of the Kaniko executor, like a mirror for the air-gapped clusters.
+	// Defaults to the KANIKO_EXECUTOR_IMAGE environment variable, else to the Kaniko release the builder is tested with.
+	ExecutorImage string `json:"executorImage,omitempty"`
 	//
 	PublishStrategyOptions map[string]string `json:"PublishStrategyOptions,omitempty"`
 }
@@ -0,0 +1,314 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// kanikoReservedFlags flags set by the builder itself, which can't be overridden by the Kaniko task additional flags
+var kanikoReservedFlags = []string{"--dockerfile", "-f", "--context", "-c", "--destination", "-d"}
+
+// reservedEnvNames the environment variables set by the builder or read by the Kaniko executor, which can't be overridden by
+// the build arguments passed through the container environment
+var reservedEnvNames = []string{
+	"PATH", "HOME", "USER", "HOSTNAME", "SSL_CERT_DIR", "DOCKER_CONFIG", "GOOGLE_APPLICATION_CREDENTIALS",
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "TRACEPARENT", "TRACESTATE",
+}
+
+var (
+	supportedBuildStrategies    = []string{string(BuildStrategyPod), string(BuildStrategyRoutine)}
+	supportedPublishStrategies  = []string{string(PlatformBuildPublishStrategyKaniko)}
+	supportedSecurityProfiles   = []string{string(SecurityProfilePrivileged), string(SecurityProfileBaseline), string(SecurityProfileRestricted)}
+	supportedSnapshotModes      = []string{string(KanikoSnapshotModeFull), string(KanikoSnapshotModeRedo), string(KanikoSnapshotModeTime)}
+	strategiesRequiringPodBuild = map[PlatformBuildPublishStrategy]bool{PlatformBuildPublishStrategyKaniko: true}
+)
+
+// Validate returns the errors found in the PlatformBuild, reported with the path of the offending fields
+func (in *PlatformBuild) Validate() field.ErrorList {
+	var errs field.ErrorList
+	if len(in.Name) == 0 {
+		errs = append(errs, field.Required(field.NewPath("meta", "name"), ""))
+	}
+	return append(errs, in.Spec.Validate(field.NewPath("spec"))...)
+}
+
+// Validate returns the errors found in the PlatformBuildSpec, reported with the path of the offending fields
+func (in *PlatformBuildSpec) Validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validateBuildStrategy(path.Child("buildStrategy"), in.BuildStrategy)...)
+	if !contains(supportedPublishStrategies, string(in.PublishStrategy)) {
+		errs = append(errs, field.NotSupported(path.Child("publishStrategy"), in.PublishStrategy, supportedPublishStrategies))
+	} else if strategiesRequiringPodBuild[in.PublishStrategy] && in.BuildStrategy != BuildStrategyPod {
+		errs = append(errs, field.Invalid(path.Child("buildStrategy"), in.BuildStrategy,
+			"publish strategy "+string(in.PublishStrategy)+" requires the "+string(BuildStrategyPod)+" build strategy"))
+	}
+	if len(in.BaseImage) > 0 {
+		errs = append(errs, validateImageReference(path.Child("baseImage"), in.BaseImage)...)
+	}
+	if len(in.ExecutorImage) > 0 {
+		errs = append(errs, validateImageReference(path.Child("executorImage"), in.ExecutorImage)...)
+	}
+	errs = append(errs, in.Registry.Validate(path.Child("registry"))...)
+	if in.Timeout != nil && in.Timeout.Duration <= 0 {
+		errs = append(errs, field.Invalid(path.Child("timeout"), in.Timeout.Duration.String(), "must be positive"))
+	}
+	return append(errs, validateSecurityProfile(path.Child("securityProfile"), in.SecurityProfile)...)
+}
+
+// Validate returns the errors found in the Build, reported with the path of the offending fields
+func (in *Build) Validate() field.ErrorList {
+	var errs field.ErrorList
+	if len(in.Name) == 0 {
+		errs = append(errs, field.Required(field.NewPath("meta", "name"), ""))
+	}
+	return append(errs, in.Spec.Validate(field.NewPath("spec"))...)
+}
+
+// Validate returns the errors found in the BuildSpec, reported with the path of the offending fields
+func (in *BuildSpec) Validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if len(in.Tasks) != 1 {
+		errs = append(errs, field.Invalid(path.Child("tasks"), len(in.Tasks), "exactly one task is required"))
+	}
+	for i := range in.Tasks {
+		taskPath := path.Child("tasks").Index(i).Child("kaniko")
+		if in.Tasks[i].Kaniko == nil {
+			errs = append(errs, field.Required(taskPath, ""))
+		} else {
+			errs = append(errs, in.Tasks[i].Kaniko.Validate(taskPath)...)
+		}
+	}
+	if len(in.Strategy) > 0 {
+		errs = append(errs, validateBuildStrategy(path.Child("strategy"), in.Strategy)...)
+	}
+	if in.Timeout.Duration < 0 {
+		errs = append(errs, field.Invalid(path.Child("timeout"), in.Timeout.Duration.String(), "must not be negative"))
+	}
+	return append(errs, validateSecurityProfile(path.Child("securityProfile"), in.SecurityProfile)...)
+}
+
+// Validate returns the errors found in the KanikoTask, reported with the path of the offending fields
+func (in *KanikoTask) Validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if len(in.Image) == 0 {
+		errs = append(errs, field.Required(path.Child("image"), "the name of the image to build"))
+	} else {
+		image := in.Image
+		if len(in.Registry.Address) > 0 {
+			// the image is pushed to the registry address
+			image = in.Registry.Address + "/" + in.Image
+		}
+		errs = append(errs, validateImageReference(path.Child("image"), image)...)
+	}
+	if len(in.BaseImage) > 0 {
+		errs = append(errs, validateImageReference(path.Child("baseImage"), in.BaseImage)...)
+	}
+	for i, flag := range in.AdditionalFlags {
+		name := strings.SplitN(flag, "=", 2)[0]
+		if contains(kanikoReservedFlags, name) {
+			errs = append(errs, field.Invalid(path.Child("additionalFlags").Index(i), flag, "the "+name+" flag is set by the builder"))
+		}
+	}
+	errs = append(errs, in.Registry.Validate(path.Child("registry"))...)
+	errs = append(errs, in.Options.Validate(path.Child("options"))...)
+	if len(in.ExecutorImage) > 0 {
+		errs = append(errs, validateImageReference(path.Child("executorImage"), in.ExecutorImage)...)
+	}
+	args := map[string]bool{}
+	for i := range in.BuildArgs {
+		argPath := path.Child("buildArgs").Index(i)
+		if args[in.BuildArgs[i].Name] {
+			errs = append(errs, field.Duplicate(argPath.Child("name"), in.BuildArgs[i].Name))
+		}
+		args[in.BuildArgs[i].Name] = true
+		errs = append(errs, in.BuildArgs[i].Validate(argPath)...)
+	}
+	secrets := map[string]bool{}
+	for i := range in.BuildSecrets {
+		secretPath := path.Child("buildSecrets").Index(i)
+		if secrets[in.BuildSecrets[i].Name] {
+			errs = append(errs, field.Duplicate(secretPath.Child("name"), in.BuildSecrets[i].Name))
+		}
+		secrets[in.BuildSecrets[i].Name] = true
+		errs = append(errs, in.BuildSecrets[i].Validate(secretPath)...)
+	}
+	return errs
+}
+
+// Validate returns the errors found in the KanikoOptions, reported with the path of the offending fields.
+// The options supported by the Kaniko executor depend on its version, which is checked by the builder.
+func (in *KanikoOptions) Validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if len(in.SnapshotMode) > 0 && !contains(supportedSnapshotModes, string(in.SnapshotMode)) {
+		errs = append(errs, field.NotSupported(path.Child("snapshotMode"), in.SnapshotMode, supportedSnapshotModes))
+	}
+	if in.PushRetry != nil && *in.PushRetry < 0 {
+		errs = append(errs, field.Invalid(path.Child("pushRetry"), *in.PushRetry, "must be greater than or equal to 0"))
+	}
+	return errs
+}
+
+// Validate returns the errors found in the BuildArg, reported with the path of the offending fields.
+// The arguments are passed to the builder through its environment, so their names must be valid environment variable names.
+func (in *BuildArg) Validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if len(in.Name) == 0 {
+		errs = append(errs, field.Required(path.Child("name"), ""))
+	} else {
+		for _, msg := range validation.IsEnvVarName(in.Name) {
+			errs = append(errs, field.Invalid(path.Child("name"), in.Name, msg))
+		}
+	}
+	if in.ValueFrom == nil {
+		return errs
+	}
+	if IsReservedEnvName(in.Name) {
+		errs = append(errs, field.Invalid(path.Child("name"), in.Name, "is reserved by the builder environment, which passes the values read from ConfigMaps and Secrets"))
+	}
+	if len(in.Value) > 0 {
+		errs = append(errs, field.Invalid(path.Child("valueFrom"), "", "must not be set along with value"))
+	}
+	configMapRef, secretRef := in.ValueFrom.ConfigMapKeyRef, in.ValueFrom.SecretKeyRef
+	switch {
+	case configMapRef == nil && secretRef == nil:
+		errs = append(errs, field.Required(path.Child("valueFrom"), "a configMapKeyRef or a secretKeyRef"))
+	case configMapRef != nil && secretRef != nil:
+		errs = append(errs, field.Invalid(path.Child("valueFrom"), "", "only one of configMapKeyRef or secretKeyRef must be set"))
+	case configMapRef != nil:
+		errs = append(errs, validateKeyRef(path.Child("valueFrom", "configMapKeyRef"), configMapRef.Name, configMapRef.Key)...)
+	default:
+		errs = append(errs, validateKeyRef(path.Child("valueFrom", "secretKeyRef"), secretRef.Name, secretRef.Key)...)
+	}
+	return errs
+}
+
+// Validate returns the errors found in the BuildSecret, reported with the path of the offending fields
+func (in *BuildSecret) Validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if len(in.Name) == 0 {
+		errs = append(errs, field.Required(path.Child("name"), ""))
+	} else {
+		for _, msg := range validation.IsDNS1123Subdomain(in.Name) {
+			errs = append(errs, field.Invalid(path.Child("name"), in.Name, msg))
+		}
+	}
+	if len(in.MountPath) > 0 && !filepath.IsAbs(in.MountPath) {
+		errs = append(errs, field.Invalid(path.Child("mountPath"), in.MountPath, "must be an absolute path"))
+	}
+	return errs
+}
+
+func validateBuildStrategy(path *field.Path, strategy BuildStrategy) field.ErrorList {
+	if !contains(supportedBuildStrategies, string(strategy)) {
+		return field.ErrorList{field.NotSupported(path, strategy, supportedBuildStrategies)}
+	}
+	return nil
+}
+
+// validateSecurityProfile an empty profile is valid, meaning the default one
+func validateSecurityProfile(path *field.Path, profile SecurityProfile) field.ErrorList {
+	if len(profile) > 0 && !contains(supportedSecurityProfiles, string(profile)) {
+		return field.ErrorList{field.NotSupported(path, profile, supportedSecurityProfiles)}
+	}
+	return nil
+}
+
+func validateKeyRef(path *field.Path, name, key string) field.ErrorList {
+	var errs field.ErrorList
+	if len(name) == 0 {
+		errs = append(errs, field.Required(path.Child("name"), ""))
+	}
+	if len(key) == 0 {
+		errs = append(errs, field.Required(path.Child("key"), ""))
+	}
+	return errs
+}
+
+// Validate returns the errors found in the RegistrySpec, reported with the path of the offending fields
+func (in *RegistrySpec) Validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	registries := map[string]bool{}
+	for i, mirror := range in.Mirrors {
+		mirrorPath := path.Child("mirrors").Index(i)
+		if registries[mirror.Registry] {
+			errs = append(errs, field.Duplicate(mirrorPath.Child("registry"), mirror.Registry))
+		} else if strings.Contains(mirror.Registry, "/") {
+			errs = append(errs, field.Invalid(mirrorPath.Child("registry"), mirror.Registry, "must be a registry host, with an optional port"))
+		} else {
+			errs = append(errs, validateRegistryLocation(mirrorPath.Child("registry"), mirror.Registry)...)
+		}
+		registries[mirror.Registry] = true
+		if len(mirror.Endpoints) == 0 {
+			errs = append(errs, field.Required(mirrorPath.Child("endpoints"), "at least one mirror of the registry"))
+		}
+		for j, endpoint := range mirror.Endpoints {
+			errs = append(errs, validateRegistryLocation(mirrorPath.Child("endpoints").Index(j).Child("location"), endpoint.Location)...)
+		}
+	}
+	return errs
+}
+
+// validateRegistryLocation checks a registry host, with an optional port and path, which images can be pulled from
+func validateRegistryLocation(path *field.Path, location string) field.ErrorList {
+	if len(location) == 0 {
+		return field.ErrorList{field.Required(path, "")}
+	}
+	if strings.Contains(location, "://") {
+		return field.ErrorList{field.Invalid(path, location, "must not have a scheme, set insecure for the http only registries")}
+	}
+	// the location must stay the domain of the images pulled from it, unlike "name/image" which is a Docker Hub image
+	if _, err := reference.ParseNamed(location + "/library/image"); err != nil {
+		return field.ErrorList{field.Invalid(path, location, "must be a registry host, with an optional port and path")}
+	}
+	return nil
+}
+
+func validateImageReference(path *field.Path, image string) field.ErrorList {
+	if _, err := reference.ParseNormalizedNamed(image); err != nil {
+		return field.ErrorList{field.Invalid(path, image, err.Error())}
+	}
+	return nil
+}
+
+// IsReservedEnvName returns true if the environment variable is set by the builder or read by the Kaniko executor, ignoring the case
+// like the proxy variables
+func IsReservedEnvName(name string) bool {
+	if strings.HasPrefix(strings.ToUpper(name), "KANIKO_") {
+		return true
+	}
+	for _, reserved := range reservedEnvNames {
+		if strings.EqualFold(name, reserved) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -22,6 +22,8 @@
 package api
 
 import (
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -58,6 +60,51 @@ func (in *Build) DeepCopy() *Build {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildArg) DeepCopyInto(out *BuildArg) {
+	*out = *in
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(BuildArgSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildArg.
+func (in *BuildArg) DeepCopy() *BuildArg {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildArg)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildArgSource) DeepCopyInto(out *BuildArgSource) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildArgSource.
+func (in *BuildArgSource) DeepCopy() *BuildArgSource {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildArgSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BuildCondition) DeepCopyInto(out *BuildCondition) {
 	*out = *in
@@ -75,6 +122,56 @@ func (in *BuildCondition) DeepCopy() *BuildCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildJobSpec) DeepCopyInto(out *BuildJobSpec) {
+	*out = *in
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PodFailurePolicy != nil {
+		in, out := &in.PodFailurePolicy, &out.PodFailurePolicy
+		*out = new(batchv1.PodFailurePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildJobSpec.
+func (in *BuildJobSpec) DeepCopy() *BuildJobSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildJobSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildSecret) DeepCopyInto(out *BuildSecret) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildSecret.
+func (in *BuildSecret) DeepCopy() *BuildSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BuildSpec) DeepCopyInto(out *BuildSpec) {
 	*out = *in
@@ -86,6 +183,11 @@ func (in *BuildSpec) DeepCopyInto(out *BuildSpec) {
 		}
 	}
 	out.Timeout = in.Timeout
+	if in.Job != nil {
+		in, out := &in.Job, &out.Job
+		*out = new(BuildJobSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildSpec.
@@ -98,9 +200,49 @@ func (in *BuildSpec) DeepCopy() *BuildSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildPhaseTransition) DeepCopyInto(out *BuildPhaseTransition) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildPhaseTransition.
+func (in *BuildPhaseTransition) DeepCopy() *BuildPhaseTransition {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildPhaseTransition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildProgress) DeepCopyInto(out *BuildProgress) {
+	*out = *in
+	if in.LastActivityAt != nil {
+		in, out := &in.LastActivityAt, &out.LastActivityAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildProgress.
+func (in *BuildProgress) DeepCopy() *BuildProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BuildStatus) DeepCopyInto(out *BuildStatus) {
 	*out = *in
+	if in.PinnedBaseImages != nil {
+		in, out := &in.PinnedBaseImages, &out.PinnedBaseImages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Failure != nil {
 		in, out := &in.Failure, &out.Failure
 		*out = new(Failure)
@@ -117,6 +259,23 @@ func (in *BuildStatus) DeepCopyInto(out *BuildStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Transitions != nil {
+		in, out := &in.Transitions, &out.Transitions
+		*out = make([]BuildPhaseTransition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Timings != nil {
+		in, out := &in.Timings, &out.Timings
+		*out = new(BuildTimings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = new(BuildProgress)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ResourceVolume != nil {
 		in, out := &in.ResourceVolume, &out.ResourceVolume
 		*out = new(ResourceVolume)
@@ -134,6 +293,65 @@ func (in *BuildStatus) DeepCopy() *BuildStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildTimings) DeepCopyInto(out *BuildTimings) {
+	*out = *in
+	if in.CreatedAt != nil {
+		in, out := &in.CreatedAt, &out.CreatedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.QueuedAt != nil {
+		in, out := &in.QueuedAt, &out.QueuedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.ScheduledAt != nil {
+		in, out := &in.ScheduledAt, &out.ScheduledAt
+		*out = (*in).DeepCopy()
+	}
+	if in.ContainerStartedAt != nil {
+		in, out := &in.ContainerStartedAt, &out.ContainerStartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.PushStartedAt != nil {
+		in, out := &in.PushStartedAt, &out.PushStartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.FinishedAt != nil {
+		in, out := &in.FinishedAt, &out.FinishedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.QueueDuration != nil {
+		in, out := &in.QueueDuration, &out.QueueDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.PullDuration != nil {
+		in, out := &in.PullDuration, &out.PullDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.BuildDuration != nil {
+		in, out := &in.BuildDuration, &out.BuildDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.PushDuration != nil {
+		in, out := &in.PushDuration, &out.PushDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildTimings.
+func (in *BuildTimings) DeepCopy() *BuildTimings {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildTimings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Failure) DeepCopyInto(out *Failure) {
 	*out = *in
@@ -167,11 +385,56 @@ func (in *FailureRecovery) DeepCopy() *FailureRecovery {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KanikoOptions) DeepCopyInto(out *KanikoOptions) {
+	*out = *in
+	if in.UseNewRun != nil {
+		in, out := &in.UseNewRun, &out.UseNewRun
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CompressedCaching != nil {
+		in, out := &in.CompressedCaching, &out.CompressedCaching
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Reproducible != nil {
+		in, out := &in.Reproducible, &out.Reproducible
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PushRetry != nil {
+		in, out := &in.PushRetry, &out.PushRetry
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SingleSnapshot != nil {
+		in, out := &in.SingleSnapshot, &out.SingleSnapshot
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Cleanup != nil {
+		in, out := &in.Cleanup, &out.Cleanup
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KanikoOptions.
+func (in *KanikoOptions) DeepCopy() *KanikoOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(KanikoOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KanikoTask) DeepCopyInto(out *KanikoTask) {
 	*out = *in
 	out.BaseTask = in.BaseTask
-	out.PublishTask = in.PublishTask
+	in.PublishTask.DeepCopyInto(&out.PublishTask)
 	if in.Verbose != nil {
 		in, out := &in.Verbose, &out.Verbose
 		*out = new(bool)
@@ -184,6 +447,19 @@ func (in *KanikoTask) DeepCopyInto(out *KanikoTask) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.BuildArgs != nil {
+		in, out := &in.BuildArgs, &out.BuildArgs
+		*out = make([]BuildArg, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BuildSecrets != nil {
+		in, out := &in.BuildSecrets, &out.BuildSecrets
+		*out = make([]BuildSecret, len(*in))
+		copy(*out, *in)
+	}
+	in.Options.DeepCopyInto(&out.Options)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KanikoTask.
@@ -216,6 +492,21 @@ func (in *KanikoTaskCache) DeepCopy() *KanikoTaskCache {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MirrorEndpoint) DeepCopyInto(out *MirrorEndpoint) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MirrorEndpoint.
+func (in *MirrorEndpoint) DeepCopy() *MirrorEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(MirrorEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ObjectReference) DeepCopyInto(out *ObjectReference) {
 	*out = *in
@@ -251,12 +542,17 @@ func (in *PlatformBuild) DeepCopy() *PlatformBuild {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PlatformBuildSpec) DeepCopyInto(out *PlatformBuildSpec) {
 	*out = *in
-	out.Registry = in.Registry
+	in.Registry.DeepCopyInto(&out.Registry)
 	if in.Timeout != nil {
 		in, out := &in.Timeout, &out.Timeout
 		*out = new(v1.Duration)
 		**out = **in
 	}
+	if in.Job != nil {
+		in, out := &in.Job, &out.Job
+		*out = new(BuildJobSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.PublishStrategyOptions != nil {
 		in, out := &in.PublishStrategyOptions, &out.PublishStrategyOptions
 		*out = make(map[string]string, len(*in))
@@ -279,7 +575,7 @@ func (in *PlatformBuildSpec) DeepCopy() *PlatformBuildSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PublishTask) DeepCopyInto(out *PublishTask) {
 	*out = *in
-	out.Registry = in.Registry
+	in.Registry.DeepCopyInto(&out.Registry)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PublishTask.
@@ -292,9 +588,36 @@ func (in *PublishTask) DeepCopy() *PublishTask {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryMirror) DeepCopyInto(out *RegistryMirror) {
+	*out = *in
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]MirrorEndpoint, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryMirror.
+func (in *RegistryMirror) DeepCopy() *RegistryMirror {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryMirror)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RegistrySpec) DeepCopyInto(out *RegistrySpec) {
 	*out = *in
+	if in.Mirrors != nil {
+		in, out := &in.Mirrors, &out.Mirrors
+		*out = make([]RegistryMirror, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistrySpec.
@@ -20,7 +20,13 @@ import (
 	"github.com/containers/buildah/define"
 	"github.com/containers/podman/v4/pkg/bindings/images"
 	"github.com/containers/podman/v4/pkg/domain/entities"
+	"github.com/docker/distribution/reference"
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/util/dockerfile"
+	"github.com/kiegroup/container-builder/util/mirrors"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"os"
 	"time"
 )
 
@@ -32,9 +38,16 @@ type BuildahVanillaConfig struct {
 	Tags               []string
 	SeccompProfilePath string
 	AddCapabilities    []string
+	// Mirrors the mirrors of the registries the base images are pulled from, see pullFromMirrors
+	Mirrors []api.RegistryMirror
 }
 
-func BuildahBuild(connection context.Context, config BuildahVanillaConfig) (string, error) {
+// BuildahBuild builds the image with the Podman service of the given connection, returning its ID.
+// The build stops with an error if the context is done before it ends.
+func BuildahBuild(ctx context.Context, connection context.Context, config BuildahVanillaConfig) (string, error) {
+	if err := pullFromMirrors(connectionContext{Context: ctx, connection: connection}, config); err != nil {
+		return "", err
+	}
 	dockerfiles := []string{config.DockerFilePath + config.DockerFileName}
 	buildOptions := define.BuildOptions{
 		AddCapabilities: config.AddCapabilities,
@@ -46,8 +59,73 @@ func BuildahBuild(connection context.Context, config BuildahVanillaConfig) (stri
 		},
 	}
 	start := time.Now()
-	report, err := images.Build(connection, dockerfiles, entities.BuildOptions{BuildOptions: buildOptions})
+	report, err := images.Build(connectionContext{Context: ctx, connection: connection}, dockerfiles, entities.BuildOptions{BuildOptions: buildOptions})
 	timeElapsed := time.Since(start)
 	logrus.Infof("The Buildah build took %s", timeElapsed)
+	if ctx.Err() != nil {
+		return "", errors.Wrap(ctx.Err(), "Buildah build stopped")
+	}
+	if report == nil {
+		return "", err
+	}
 	return report.ID, err
 }
+
+// connectionContext carries the values of the Podman connection, like its client, and the cancellation of the caller context
+type connectionContext struct {
+	context.Context
+	connection context.Context
+}
+
+func (c connectionContext) Value(key interface{}) interface{} {
+	if value := c.Context.Value(key); value != nil {
+		return value
+	}
+	return c.connection.Value(key)
+}
+
+// pullFromMirrors pulls the base images of the Dockerfile from the mirrors of their registry, tried in order, and tags them
+// with their own name so that the build finds them instead of pulling them from the registry. The mirrors are only used by
+// this build, the configuration of the Podman service is left untouched. The images referenced by digest, depending on the
+// build arguments or not found in any mirror are pulled by the build from their registry.
+func pullFromMirrors(ctx context.Context, config BuildahVanillaConfig) error {
+	if len(config.Mirrors) == 0 {
+		return nil
+	}
+	content, err := os.ReadFile(config.DockerFilePath + config.DockerFileName)
+	if err != nil {
+		return errors.Wrap(err, "cannot read the Dockerfile")
+	}
+	baseImages, err := dockerfile.BaseImages(content)
+	if err != nil {
+		logrus.Warnf("The base images are not pulled from the registry mirrors: %v", err)
+		return nil
+	}
+	for _, image := range baseImages {
+		named, err := reference.ParseNormalizedNamed(image)
+		if err != nil {
+			return errors.Wrapf(err, "invalid base image %s", image)
+		}
+		tagged, ok := reference.TagNameOnly(named).(reference.Tagged)
+		if !ok {
+			// the build wouldn't find the image by its digest under the name of the mirror
+			continue
+		}
+		mirrored, err := mirrors.Images(config.Mirrors, image)
+		if err != nil {
+			return err
+		}
+		for _, mirror := range mirrored {
+			ids, err := images.Pull(ctx, mirror.Name, new(images.PullOptions).WithQuiet(true).WithSkipTLSVerify(mirror.Insecure))
+			if err == nil && len(ids) > 0 {
+				err = images.Tag(ctx, ids[0], tagged.Tag(), named.Name(), nil)
+			}
+			if err == nil {
+				logrus.Infof("Pulled the base image %s from the mirror %s", image, mirror.Name)
+				break
+			}
+			logrus.Warnf("Cannot pull the base image %s from the mirror %s: %v", image, mirror.Name, err)
+		}
+	}
+	return nil
+}
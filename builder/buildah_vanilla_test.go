@@ -18,6 +18,7 @@
 package builder
 
 import (
+	"context"
 	"github.com/kiegroup/container-builder/common"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -55,7 +56,7 @@ func (suite *BuildahTestSuite) TestBuildahBuild() {
 		AddCapabilities:    []string{"all"},
 	}
 	connection, _ := common.GetRootlessPodmanConnection()
-	id, err := BuildahBuild(connection, config)
+	id, err := BuildahBuild(context.Background(), connection, config)
 
 	assert.NotNil(suite.T(), id)
 	assert.Nil(suite.T(), err)
@@ -18,6 +18,7 @@
 package builder
 
 import (
+	"context"
 	"github.com/kiegroup/container-builder/common"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -52,7 +53,7 @@ func (suite *KanikoDockerTestSuite) TestKanikoBuild() {
 	}
 	logrus.Infof("Start Kaniko build")
 	start := time.Now()
-	imageID, error := KanikoBuild(suite.Docker.Connection, config)
+	imageID, error := KanikoBuild(context.Background(), suite.Docker.Connection, config)
 	timeElapsed := time.Since(start)
 	logrus.Infof("The Kaniko build took %s", timeElapsed)
 	assert.Nil(suite.T(), error, "Build failed")
@@ -21,6 +21,10 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/util/defaults"
+	"github.com/kiegroup/container-builder/util/mirrors"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"os"
 )
@@ -33,11 +37,22 @@ type KanikoVanillaConfig struct {
 	VerbosityLevel         string
 	ContainerName          string
 	ReadBuildOutput        bool
+	// Mirrors the mirrors of the registries the images are pulled from
+	Mirrors []api.RegistryMirror
 }
 
-const EXECUTOR_IMAGE = "gcr.io/kaniko-project/executor:latest"
+// EXECUTOR_IMAGE the Kaniko executor image used when neither the config nor the environment set any,
+// see KanikoVanillaConfig.GetExecutorImage
+const EXECUTOR_IMAGE = defaults.KanikoExecutorImage
 
-func KanikoBuild(connection *client.Client, config KanikoVanillaConfig) (string, error) {
+// GetExecutorImage returns the configured Kaniko executor image, else the one of the environment, else EXECUTOR_IMAGE
+func (c KanikoVanillaConfig) GetExecutorImage() string {
+	return defaults.GetKanikoExecutorImage(c.KanikoExecutorImage)
+}
+
+// KanikoBuild runs the Kaniko executor in a Docker container, returning its ID.
+// The container is killed if the context is done before the build ends.
+func KanikoBuild(ctx context.Context, connection *client.Client, config KanikoVanillaConfig) (string, error) {
 
 	hostConfig := &container.HostConfig{
 		NetworkMode: "host",
@@ -46,16 +61,15 @@ func KanikoBuild(connection *client.Client, config KanikoVanillaConfig) (string,
 		},
 	}
 
-	ctx := context.Background()
 	resp, err := connection.ContainerCreate(ctx, &container.Config{
-		Image: config.KanikoExecutorImage,
-		Cmd: []string{
+		Image: config.GetExecutorImage(),
+		Cmd: append([]string{
 			"-f", config.DockerFileName,
 			"-d", config.RegistryFinalImageName,
 			"-c", "/workspace",
 			"--force",
 			"--verbosity", config.VerbosityLevel,
-		},
+		}, mirrors.KanikoFlags(config.Mirrors)...),
 		Tty:     false,
 		Volumes: map[string]struct{}{},
 	}, hostConfig, nil, nil, config.ContainerName)
@@ -78,6 +92,12 @@ func KanikoBuild(connection *client.Client, config KanikoVanillaConfig) (string,
 			logrus.Error(err)
 		}
 	case <-statusCh:
+	case <-ctx.Done():
+		// the caller context can't be used anymore to stop the container
+		if err := connection.ContainerKill(context.Background(), resp.ID, "SIGKILL"); err != nil {
+			logrus.Error(err)
+		}
+		return resp.ID, errors.Wrap(ctx.Err(), "Kaniko build stopped")
 	}
 
 	out, err := connection.ContainerLogs(ctx, resp.ID, types.ContainerLogsOptions{ShowStdout: true})
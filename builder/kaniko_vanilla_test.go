@@ -0,0 +1,37 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package builder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/util/defaults"
+)
+
+func TestKanikoExecutorImage(t *testing.T) {
+	t.Setenv(defaults.KanikoExecutorImageEnv, "")
+	assert.Equal(t, EXECUTOR_IMAGE, KanikoVanillaConfig{}.GetExecutorImage())
+
+	// the local builds resolve the executor image like the builds on Kubernetes
+	t.Setenv(defaults.KanikoExecutorImageEnv, "registry.example.com/kaniko/executor:v1.9.0")
+	assert.Equal(t, "registry.example.com/kaniko/executor:v1.9.0", KanikoVanillaConfig{}.GetExecutorImage())
+	assert.Equal(t, api.KanikoTask{}.GetExecutorImage(), KanikoVanillaConfig{}.GetExecutorImage())
+	assert.Equal(t, "quay.io/kaniko/executor:v1.8.1", KanikoVanillaConfig{KanikoExecutorImage: "quay.io/kaniko/executor:v1.8.1"}.GetExecutorImage())
+}
@@ -37,17 +37,43 @@ type Action interface {
 	Handle(ctx context.Context, build *api.Build) (*api.Build, error)
 }
 
-type baseAction struct {
+// ActionStage when a custom Action is invoked by the builder, relative to the action handling the build phase
+type ActionStage string
+
+const (
+	// ActionStageBefore the action is invoked before the action handling the build phase, like a policy check.
+	// Returning an error stops the reconciliation, changing the build phase skips the action handling the previous phase.
+	ActionStageBefore ActionStage = "before"
+	// ActionStageHandle the action handles the build phase, taking precedence over the built-in actions when it can handle the build.
+	ActionStageHandle ActionStage = "handle"
+	// ActionStageAfter the action is invoked once the build entered the phase, like a notification.
+	// Returning an error is logged without stopping the reconciliation, the action being invoked once per transition.
+	ActionStageAfter ActionStage = "after"
+)
+
+// customAction an Action registered by client code for the builds in a given phase
+type customAction struct {
+	phase  api.BuildPhase
+	stage  ActionStage
+	action Action
+}
+
+// BaseAction is embedded by the actions to get the logger and the client injected by the builder.
+// Custom actions can embed it as well.
+type BaseAction struct {
 	client client.Client
 	L      log.Logger
 }
 
-// TODO: implement our client wrapper
-
-func (action *baseAction) InjectClient(client client.Client) {
+func (action *BaseAction) InjectClient(client client.Client) {
 	action.client = client
 }
 
-func (action *baseAction) InjectLogger(log log.Logger) {
+func (action *BaseAction) InjectLogger(log log.Logger) {
 	action.L = log
 }
+
+// Client returns the client injected by the builder. Objects created with it are decorated by the builder ObjectDecorators.
+func (action *BaseAction) Client() client.Client {
+	return action.client
+}
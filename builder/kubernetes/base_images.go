@@ -0,0 +1,164 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/client"
+	"github.com/kiegroup/container-builder/util/dockerfile"
+	"github.com/kiegroup/container-builder/util/registry"
+)
+
+// StaleBuild a build whose base image moved since it was built
+type StaleBuild struct {
+	Build *api.Build `json:"build"`
+	// BaseImage the base image pinned by the build, like "quay.io/kiegroup/kogito-swf-builder:latest@sha256:..."
+	BaseImage string `json:"baseImage"`
+	// CurrentDigest the digest the base image tag points to now, empty if the tag doesn't exist anymore
+	CurrentDigest string `json:"currentDigest,omitempty"`
+}
+
+// FindStaleBuilds returns the succeeded builds whose pinned base images moved since they were built, once per moved image.
+// The builds without pinned base images are skipped, see api.BuildSpec PinBaseImages.
+func FindStaleBuilds(ctx context.Context, c client.Client, builds []*api.Build) ([]StaleBuild, error) {
+	var stale []StaleBuild
+	for _, build := range builds {
+		task := kanikoTask(build)
+		if build.Status.Phase != api.BuildPhaseSucceeded || len(build.Status.PinnedBaseImages) == 0 || task == nil {
+			continue
+		}
+		registryClient, err := newRegistryClient(ctx, c, build.Namespace, task.Registry)
+		if err != nil {
+			return nil, err
+		}
+		for _, image := range build.Status.PinnedBaseImages {
+			current, moved, err := baseImageMoved(ctx, registryClient, image)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot check the base images of build %s", build.Name)
+			}
+			if moved {
+				stale = append(stale, StaleBuild{Build: build, BaseImage: image, CurrentDigest: current.String()})
+			}
+		}
+	}
+	return stale, nil
+}
+
+// pinBaseImages rewrites the FROM instructions of the Dockerfile and the task base image with the current digest of the images,
+// recording them in the status. The images depending on the build arguments are left as they are, they can't be known before building.
+func (s *scheduler) pinBaseImages(ctx context.Context) error {
+	build := s.builder.Context.Build
+	task := kanikoTask(build)
+	if !build.Spec.PinBaseImages || task == nil {
+		return nil
+	}
+	registryClient, err := newRegistryClient(ctx, s.builder.Context.Client, build.Namespace, task.Registry)
+	if err != nil {
+		return err
+	}
+	pinned := map[string]string{}
+	pin := func(image string) (string, error) {
+		if p, ok := pinned[image]; ok {
+			return p, nil
+		}
+		p, err := pinImage(ctx, registryClient, image)
+		if err != nil {
+			return "", err
+		}
+		pinned[image] = p
+		build.Status.PinnedBaseImages = append(build.Status.PinnedBaseImages, p)
+		return p, nil
+	}
+
+	for i, r := range s.Resources {
+		if r.Target != dockerfileName {
+			continue
+		}
+		content, final, err := dockerfile.PinBaseImages(r.Content, pin)
+		if err != nil {
+			return err
+		}
+		s.Resources[i].Content = content
+		if !strings.Contains(final, "$") {
+			build.Status.BaseImage = final
+		}
+	}
+	if len(task.BaseImage) > 0 {
+		if task.BaseImage, err = pin(task.BaseImage); err != nil {
+			return err
+		}
+		if len(build.Status.BaseImage) == 0 {
+			build.Status.BaseImage = task.BaseImage
+		}
+	}
+	s.builder.L.Infof("Build %s pinned the base images %s", build.Name, strings.Join(build.Status.PinnedBaseImages, ", "))
+	return nil
+}
+
+// pinImage returns the image pinned to the current digest of its tag, like "busybox:latest@sha256:...".
+// The images already referenced by digest are returned as they are.
+func pinImage(ctx context.Context, c *registry.Client, image string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid base image %s", image)
+	}
+	if _, ok := named.(reference.Digested); ok {
+		return image, nil
+	}
+	d, err := c.Digest(ctx, image)
+	if err != nil {
+		return "", err
+	}
+	if len(d) == 0 {
+		return "", errors.Errorf("base image %s not found", image)
+	}
+	pinned, err := reference.WithDigest(reference.TagNameOnly(named), d)
+	if err != nil {
+		return "", err
+	}
+	return reference.FamiliarString(pinned), nil
+}
+
+// baseImageMoved returns the current digest of the pinned image tag and whether it's not the pinned digest anymore.
+// The images pinned without tag can't move.
+func baseImageMoved(ctx context.Context, c *registry.Client, image string) (digest.Digest, bool, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", false, errors.Wrapf(err, "invalid base image %s", image)
+	}
+	tagged, isTagged := named.(reference.Tagged)
+	digested, isDigested := named.(reference.Digested)
+	if !isTagged || !isDigested {
+		return "", false, nil
+	}
+	ref, err := reference.WithTag(reference.TrimNamed(named), tagged.Tag())
+	if err != nil {
+		return "", false, err
+	}
+	current, err := c.Digest(ctx, ref.String())
+	if err != nil {
+		return "", false, err
+	}
+	return current, current != digested.Digest(), nil
+}
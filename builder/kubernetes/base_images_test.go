@@ -0,0 +1,100 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/util/test"
+)
+
+func TestPinBaseImages(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+	builderDigest := pushTestImage(t, server.URL, "builder", "2", nil)
+	baseDigest := pushTestImage(t, server.URL, "base", "1", nil)
+
+	c, err := test.NewFakeClient()
+	assert.NoError(t, err)
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{
+			Namespace: "test",
+			Name:      "testPlatform",
+		},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy:   api.BuildStrategyPod,
+			PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+			Registry:        api.RegistrySpec{Address: host, Insecure: true},
+			Timeout:         &metav1.Duration{Duration: 5 * time.Minute},
+			PinBaseImages:   true,
+		},
+	}
+	dockerFile := "ARG TOOLS\n" +
+		"FROM --platform=linux/amd64 " + host + "/builder:2 AS build\n" +
+		"FROM ${TOOLS} AS tools\n" +
+		"FROM build AS tests\n" +
+		"from " + host + "/base:1\n" +
+		"COPY --from=build /deployments /deployments\n"
+
+	scheduler, err := NewBuild(BuilderInfo{FinalImageName: "app:latest", BuildUniqueName: "pinned", Platform: platform})
+	assert.NoError(t, err)
+	build, err := scheduler.WithClient(c).WithResource("Dockerfile", []byte(dockerFile)).Schedule(context.TODO())
+	assert.NoError(t, err)
+
+	pinnedBuilder := host + "/builder:2@" + builderDigest.String()
+	pinnedBase := host + "/base:1@" + baseDigest.String()
+	assert.Equal(t, pinnedBase, build.Status.BaseImage)
+	assert.Equal(t, []string{pinnedBuilder, pinnedBase}, build.Status.PinnedBaseImages)
+
+	configMap := &corev1.ConfigMap{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: "test", Name: build.Status.ResourceVolume.ReferenceName}, configMap))
+	assert.Equal(t, "ARG TOOLS\n"+
+		"FROM --platform=linux/amd64 "+pinnedBuilder+" AS build\n"+
+		"FROM ${TOOLS} AS tools\n"+
+		"FROM build AS tests\n"+
+		"from "+pinnedBase+"\n"+
+		"COPY --from=build /deployments /deployments\n", configMap.Data["Dockerfile"])
+
+	// the build is stale once the base image tag moves
+	build.Status.Phase = api.BuildPhaseSucceeded
+	stale, err := FindStaleBuilds(context.TODO(), c, []*api.Build{build})
+	assert.NoError(t, err)
+	assert.Empty(t, stale)
+
+	movedDigest := pushTestImage(t, server.URL, "base", "1", map[string]string{"version": "2"})
+	stale, err = FindStaleBuilds(context.TODO(), c, []*api.Build{build})
+	assert.NoError(t, err)
+	assert.Equal(t, []StaleBuild{{Build: build, BaseImage: pinnedBase, CurrentDigest: movedDigest.String()}}, stale)
+
+	// the build fails to schedule when a base image can't be pinned
+	scheduler, err = NewBuild(BuilderInfo{FinalImageName: "app:latest", BuildUniqueName: "missing", Platform: platform})
+	assert.NoError(t, err)
+	_, err = scheduler.WithClient(c).WithResource("Dockerfile", []byte("FROM "+host+"/missing:1\n")).Schedule(context.TODO())
+	assert.ErrorContains(t, err, "base image "+host+"/missing:1 not found")
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/client"
+	"github.com/kiegroup/container-builder/util/log"
+)
+
+// buildSecretsDir the directory of the build secrets without mount path, Kaniko leaves it out of the built image
+const buildSecretsDir = "/kaniko/secrets"
+
+// addBuildArgs passes the build arguments to Kaniko. The values read from ConfigMaps and Secrets are passed by reference
+// through the container environment, Kaniko reading the value of the "--build-arg=NAME" flags from the variable with the same name.
+// The literal values of the secret keys are added to secretValues instead, see addSecretBuildArgs.
+func addBuildArgs(r *log.Redactor, task *api.KanikoTask, args *[]string, env *[]corev1.EnvVar, secretValues map[string]string) {
+	for _, arg := range task.BuildArgs {
+		if arg.ValueFrom == nil && r.IsSecretKey(arg.Name) {
+			*args = append(*args, "--build-arg="+arg.Name)
+			secretValues[arg.Name] = arg.Value
+			continue
+		}
+		if arg.ValueFrom == nil {
+			*args = append(*args, "--build-arg="+arg.Name+"="+arg.Value)
+			continue
+		}
+		*args = append(*args, "--build-arg="+arg.Name)
+		*env = append(*env, corev1.EnvVar{
+			Name: arg.Name,
+			ValueFrom: &corev1.EnvVarSource{
+				ConfigMapKeyRef: arg.ValueFrom.ConfigMapKeyRef.DeepCopy(),
+				SecretKeyRef:    arg.ValueFrom.SecretKeyRef.DeepCopy(),
+			},
+		})
+	}
+}
+
+// addBuildSecrets mounts the build secrets in the Kaniko container
+func addBuildSecrets(task *api.KanikoTask, volumes *[]corev1.Volume, volumeMounts *[]corev1.VolumeMount) {
+	for i, secret := range task.BuildSecrets {
+		name := fmt.Sprintf("build-secret-%d", i)
+		*volumes = append(*volumes, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: secret.Name},
+			},
+		})
+		*volumeMounts = append(*volumeMounts, corev1.VolumeMount{
+			Name:      name,
+			MountPath: buildSecretMountPath(secret),
+			ReadOnly:  true,
+		})
+	}
+}
+
+func buildSecretMountPath(secret api.BuildSecret) string {
+	if len(secret.MountPath) > 0 {
+		return secret.MountPath
+	}
+	return path.Join(buildSecretsDir, secret.Name)
+}
+
+// validateBuildArgsAndSecrets returns an error for every ConfigMap or Secret referenced by the Kaniko tasks which doesn't exist.
+// Only the objects are checked, their values are never read.
+func validateBuildArgsAndSecrets(ctx context.Context, c client.Client, build *api.Build) field.ErrorList {
+	var errs field.ErrorList
+	for i, task := range build.Spec.Tasks {
+		if task.Kaniko == nil {
+			continue
+		}
+		taskPath := field.NewPath("spec", "tasks").Index(i).Child("kaniko")
+		for j, arg := range task.Kaniko.BuildArgs {
+			if arg.ValueFrom == nil {
+				continue
+			}
+			argPath := taskPath.Child("buildArgs").Index(j).Child("valueFrom")
+			if ref := arg.ValueFrom.ConfigMapKeyRef; ref != nil && (ref.Optional == nil || !*ref.Optional) {
+				errs = append(errs, validateObjectExists(ctx, c, argPath.Child("configMapKeyRef", "name"), build.Namespace, ref.Name, &corev1.ConfigMap{})...)
+			}
+			if ref := arg.ValueFrom.SecretKeyRef; ref != nil && (ref.Optional == nil || !*ref.Optional) {
+				errs = append(errs, validateObjectExists(ctx, c, argPath.Child("secretKeyRef", "name"), build.Namespace, ref.Name, &corev1.Secret{})...)
+			}
+		}
+		for j, secret := range task.Kaniko.BuildSecrets {
+			errs = append(errs, validateObjectExists(ctx, c, taskPath.Child("buildSecrets").Index(j).Child("name"), build.Namespace, secret.Name, &corev1.Secret{})...)
+		}
+	}
+	return errs
+}
+
+func validateObjectExists(ctx context.Context, c client.Client, path *field.Path, namespace, name string, obj ctrl.Object) field.ErrorList {
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, obj); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return field.ErrorList{field.NotFound(path, name)}
+		}
+		return field.ErrorList{field.InternalError(path, err)}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/util/test"
+)
+
+func TestBuildArgsAndSecrets(t *testing.T) {
+	ns := "test"
+	buildFile, err := os.Open("../../examples/api/Build_usingBuildArgsAndSecrets.yaml")
+	assert.NoError(t, err)
+	defer buildFile.Close()
+	builds, err := LoadBuilds(buildFile)
+	assert.NoError(t, err)
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{Namespace: ns, Name: "testPlatform"},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy:   api.BuildStrategyPod,
+			PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+		},
+	}
+
+	// the referenced objects must exist
+	c, err := test.NewFakeClient()
+	assert.NoError(t, err)
+	scheduler, err := NewBuildFromDefinition(platform, builds[0])
+	assert.NoError(t, err)
+	err = scheduler.WithClient(c).Validate(context.TODO())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `spec.tasks[0].kaniko.buildArgs[1].valueFrom.configMapKeyRef.name: Not found: "maven-mirror"`)
+	assert.Contains(t, err.Error(), `spec.tasks[0].kaniko.buildArgs[2].valueFrom.secretKeyRef.name: Not found: "maven-credentials"`)
+	assert.Contains(t, err.Error(), `spec.tasks[0].kaniko.buildSecrets[0].name: Not found: "maven-mirror"`)
+
+	c, err = test.NewFakeClient(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "maven-mirror", Namespace: ns}, Data: map[string]string{"mirror": "https://maven.example.com"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "maven-credentials", Namespace: ns}, StringData: map[string]string{"token": "s3cr3t-t0ken"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "maven-mirror", Namespace: ns}, StringData: map[string]string{"settings.xml": "<settings>s3cr3t-s3tt1ngs</settings>"}},
+	)
+	assert.NoError(t, err)
+	scheduler, err = NewBuildFromDefinition(platform, builds[0])
+	assert.NoError(t, err)
+	build, err := scheduler.WithClient(c).
+		WithResource("Dockerfile", []byte("FROM busybox\nARG MAVEN_REPO_TOKEN\n")).
+		WithBuildArgs([]api.BuildArg{{Name: "DEBUG", Value: "true"}, {Name: "API_TOKEN", Value: "l1t3ral-t0ken"}}).
+		Schedule(context.TODO())
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		build, err = FromBuild(build).WithClient(c).Reconcile(context.TODO())
+		assert.NoError(t, err)
+	}
+
+	pod, err := getBuilderPod(context.TODO(), c, build)
+	assert.NoError(t, err)
+	container := pod.Spec.Containers[0]
+	assert.Contains(t, container.Args, "--build-arg=QUARKUS_VERSION=2.16.0.Final")
+	assert.Contains(t, container.Args, "--build-arg=MAVEN_MIRROR_URL")
+	assert.Contains(t, container.Args, "--build-arg=MAVEN_REPO_TOKEN")
+	assert.Contains(t, container.Args, "--build-arg=DEBUG=true")
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: "MAVEN_REPO_TOKEN", ValueFrom: &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "maven-credentials"}, Key: "token"},
+	}})
+	assert.Contains(t, container.VolumeMounts, corev1.VolumeMount{Name: "build-secret-0", MountPath: "/kaniko/secrets/maven-mirror", ReadOnly: true})
+	assert.Contains(t, pod.Spec.Volumes, corev1.Volume{Name: "build-secret-0", VolumeSource: corev1.VolumeSource{
+		Secret: &corev1.SecretVolumeSource{SecretName: "maven-mirror"},
+	}})
+
+	// the literal values of the secret keys are read from a Secret too
+	assert.NotContains(t, container.Args, "--build-arg=API_TOKEN=l1t3ral-t0ken")
+	assert.Contains(t, container.Args, "--build-arg=API_TOKEN")
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: "API_TOKEN", ValueFrom: &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: buildArgsSecretName(build)}, Key: "API_TOKEN"},
+	}})
+	secret := &corev1.Secret{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: ns, Name: buildArgsSecretName(build)}, secret))
+	assert.Equal(t, "l1t3ral-t0ken", string(secret.Data["API_TOKEN"]))
+	for _, container := range pod.Spec.Containers {
+		for _, arg := range container.Args {
+			assert.NotContains(t, arg, "l1t3ral-t0ken")
+		}
+	}
+
+	// the secret values are never written in the builder Pod nor in the build
+	for _, object := range []interface{}{pod, build} {
+		data, err := json.Marshal(object)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "s3cr3t")
+	}
+}
+
+func TestValidateBuildArgsAndSecrets(t *testing.T) {
+	task := api.KanikoTask{
+		PublishTask: api.PublishTask{Image: "greetings:latest"},
+		BuildArgs: []api.BuildArg{
+			{Name: "1INVALID"},
+			{Name: "BOTH", Value: "value", ValueFrom: &api.BuildArgSource{SecretKeyRef: &corev1.SecretKeySelector{Key: "key"}}},
+			{Name: "BOTH"},
+			{Name: "NONE", ValueFrom: &api.BuildArgSource{}},
+			{Name: "http_proxy", Value: "http://proxy.example.com:3128"},
+			{Name: "HTTP_PROXY", ValueFrom: &api.BuildArgSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "proxy"}, Key: "url"}}},
+			{Name: "KANIKO_DIR", ValueFrom: &api.BuildArgSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "kaniko"}, Key: "dir"}}},
+		},
+		BuildSecrets: []api.BuildSecret{{Name: "Invalid_Name", MountPath: "relative"}},
+	}
+	errs := task.Validate(nil).ToAggregate().Error()
+	assert.Contains(t, errs, "buildArgs[0].name: Invalid value")
+	assert.Contains(t, errs, "buildArgs[1].valueFrom: Invalid value: \"\": must not be set along with value")
+	assert.Contains(t, errs, "buildArgs[1].valueFrom.secretKeyRef.name: Required value")
+	assert.Contains(t, errs, "buildArgs[2].name: Duplicate value: \"BOTH\"")
+	assert.Contains(t, errs, "buildArgs[3].valueFrom: Required value")
+	// the literal values don't go through the container environment
+	assert.NotContains(t, errs, "buildArgs[4]")
+	assert.Contains(t, errs, "buildArgs[5].name: Invalid value: \"HTTP_PROXY\": is reserved by the builder environment")
+	assert.Contains(t, errs, "buildArgs[6].name: Invalid value: \"KANIKO_DIR\": is reserved by the builder environment")
+	assert.Contains(t, errs, "buildSecrets[0].name: Invalid value")
+	assert.Contains(t, errs, "buildSecrets[0].mountPath: Invalid value")
+}
+
+func TestValidateReservedSecretBuildArgs(t *testing.T) {
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{Namespace: "test", Name: "testPlatform"},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy:   api.BuildStrategyPod,
+			PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+		},
+	}
+	scheduler, err := NewBuild(BuilderInfo{FinalImageName: "greetings:latest", BuildUniqueName: "reserved", Platform: platform})
+	assert.NoError(t, err)
+	err = scheduler.WithAdditionalArgs([]string{"--build-arg=GOOGLE_APPLICATION_CREDENTIALS=s3cr3t", "--build-arg=API_TOKEN=s3cr3t"}).
+		WithBuildArgs([]api.BuildArg{{Name: "KANIKO_DIR_SECRET", Value: "s3cr3t"}, {Name: "KANIKO_DIR", Value: "/kaniko"}}).
+		Validate(context.TODO())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `spec.tasks[0].kaniko.additionalFlags: Invalid value: "--build-arg=GOOGLE_APPLICATION_CREDENTIALS": is reserved by the builder environment`)
+	assert.Contains(t, err.Error(), `spec.tasks[0].kaniko.buildArgs[0].name: Invalid value: "KANIKO_DIR_SECRET": is reserved by the builder environment`)
+	// the literal values of the other keys don't go through the container environment
+	assert.NotContains(t, err.Error(), "buildArgs[1]")
+	assert.NotContains(t, err.Error(), "API_TOKEN")
+	assert.NotContains(t, err.Error(), "s3cr3t")
+}
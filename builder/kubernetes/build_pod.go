@@ -4,18 +4,25 @@ import (
 	"context"
 	"os"
 	"path"
+	"sort"
 	"strings"
 
 	"github.com/kiegroup/container-builder/client"
 	"github.com/pkg/errors"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/kiegroup/container-builder/api"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// jobNameLabel label added by the Job controller to every Pod it creates
+const jobNameLabel = "job-name"
+
 type registrySecret struct {
 	fileName    string
 	mountPath   string
@@ -33,8 +40,8 @@ func newBuildPod(ctx context.Context, c client.Client, build *api.Build) (*corev
 			Namespace: build.Namespace,
 			Name:      buildPodName(build),
 			Labels: map[string]string{
-				"kie.kogito.org/buildContext": build.Name,
-				"kie.kogito.org/component":    "builder",
+				buildContextLabel:          build.Name,
+				"kie.kogito.org/component": "builder",
 			},
 		},
 		Spec: corev1.PodSpec{
@@ -51,15 +58,82 @@ func newBuildPod(ctx context.Context, c client.Client, build *api.Build) (*corev
 			}
 		}
 	}
+	injectTraceContext(ctx, pod)
 
 	return pod, nil
 }
 
+// newBuildJob creates a Job running the builder Pod, used when the Build requires it.
+func newBuildJob(ctx context.Context, c client.Client, build *api.Build) (*batchv1.Job, error) {
+	pod, err := newBuildPod(ctx, c, build)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := build.Spec.Job
+	backoffLimit := int32(0)
+	if spec.BackoffLimit != nil {
+		backoffLimit = *spec.BackoffLimit
+	}
+	activeDeadlineSeconds := spec.ActiveDeadlineSeconds
+	if activeDeadlineSeconds == nil && build.Spec.Timeout.Duration > 0 {
+		timeout := int64(build.Spec.Timeout.Duration.Seconds())
+		activeDeadlineSeconds = &timeout
+	}
+
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: build.Namespace,
+			Name:      buildJobName(build),
+			Labels:    pod.Labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            &backoffLimit,
+			ActiveDeadlineSeconds:   activeDeadlineSeconds,
+			TTLSecondsAfterFinished: spec.TTLSecondsAfterFinished,
+			PodFailurePolicy:        spec.PodFailurePolicy,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      pod.Labels,
+					Annotations: pod.Annotations,
+				},
+				Spec: pod.Spec,
+			},
+		},
+	}, nil
+}
+
 func buildPodName(build *api.Build) string {
 	return "kogito-" + strings.ToLower(build.Name) + "-builder"
 }
 
+func buildJobName(build *api.Build) string {
+	return buildPodName(build)
+}
+
+func getBuilderJob(ctx context.Context, c client.Client, build *api.Build) (*batchv1.Job, error) {
+	job := batchv1.Job{}
+	err := c.Get(ctx, types.NamespacedName{Name: buildJobName(build), Namespace: build.Namespace}, &job)
+	if err != nil && k8serrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// getBuilderPod returns the builder Pod. When the build runs in a Job, the most recent Pod created by the Job is returned.
 func getBuilderPod(ctx context.Context, c client.Client, build *api.Build) (*corev1.Pod, error) {
+	if build.Spec.Job != nil {
+		return getBuilderJobPod(ctx, c, build)
+	}
+
 	pod := corev1.Pod{}
 	err := c.Get(ctx, types.NamespacedName{Name: buildPodName(build), Namespace: build.Namespace}, &pod)
 	if err != nil && k8serrors.IsNotFound(err) {
@@ -72,7 +146,32 @@ func getBuilderPod(ctx context.Context, c client.Client, build *api.Build) (*cor
 	return &pod, nil
 }
 
+func getBuilderJobPod(ctx context.Context, c client.Client, build *api.Build) (*corev1.Pod, error) {
+	pods := corev1.PodList{}
+	err := c.List(ctx, &pods, ctrl.InNamespace(build.Namespace), ctrl.MatchingLabels{jobNameLabel: buildJobName(build)})
+	if err != nil {
+		return nil, err
+	}
+	if len(pods.Items) == 0 {
+		return nil, nil
+	}
+
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[j].CreationTimestamp.Before(&pods.Items[i].CreationTimestamp)
+	})
+	return &pods.Items[0], nil
+}
+
+// deleteBuilderPod deletes the builder Pod and the Secret of its secret build arguments.
+// When the build runs in a Job, the Job is deleted along with its Pods.
 func deleteBuilderPod(ctx context.Context, c client.Client, build *api.Build) error {
+	if err := deleteBuildArgsSecret(ctx, c, build); err != nil {
+		return err
+	}
+	if build.Spec.Job != nil {
+		return deleteBuilderJob(ctx, c, build)
+	}
+
 	pod := corev1.Pod{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: corev1.SchemeGroupVersion.String(),
@@ -92,6 +191,26 @@ func deleteBuilderPod(ctx context.Context, c client.Client, build *api.Build) er
 	return err
 }
 
+func deleteBuilderJob(ctx context.Context, c client.Client, build *api.Build) error {
+	job := batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: build.Namespace,
+			Name:      buildJobName(build),
+		},
+	}
+
+	err := c.Delete(ctx, &job, ctrl.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err != nil && k8serrors.IsNotFound(err) {
+		return nil
+	}
+
+	return err
+}
+
 func getRegistrySecret(ctx context.Context, c client.Client, ns, name string, registrySecrets []registrySecret) (registrySecret, error) {
 	secret := corev1.Secret{}
 	err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: ns}, &secret)
@@ -106,6 +225,28 @@ func getRegistrySecret(ctx context.Context, c client.Client, ns, name string, re
 	return registrySecret{}, errors.New("unsupported secret type for registry authentication")
 }
 
+// validateSecrets checks that the registry secrets referenced by the build tasks exist and are supported
+func validateSecrets(ctx context.Context, c client.Client, build *api.Build) field.ErrorList {
+	var errs field.ErrorList
+	for i, task := range build.Spec.Tasks {
+		if task.Kaniko == nil || task.Kaniko.Registry.Secret == "" {
+			continue
+		}
+		path := field.NewPath("spec", "tasks").Index(i).Child("kaniko", "registry", "secret")
+		name := task.Kaniko.Registry.Secret
+		if _, err := getRegistrySecret(ctx, c, build.Namespace, name, kanikoRegistrySecrets); err != nil {
+			if k8serrors.IsNotFound(err) {
+				errs = append(errs, field.NotFound(path, name))
+			} else if _, ok := err.(k8serrors.APIStatus); ok {
+				errs = append(errs, field.InternalError(path, err))
+			} else {
+				errs = append(errs, field.Invalid(path, name, err.Error()))
+			}
+		}
+	}
+	return errs
+}
+
 func addRegistrySecret(name string, secret registrySecret, volumes *[]corev1.Volume, volumeMounts *[]corev1.VolumeMount, env *[]corev1.EnvVar) {
 	*volumes = append(*volumes, corev1.Volume{
 		Name: "registry-secret",
@@ -3,17 +3,31 @@ package kubernetes
 import (
 	"context"
 	"fmt"
+	"io"
+
 	"github.com/kiegroup/container-builder/api"
 	"github.com/kiegroup/container-builder/client"
 	"github.com/kiegroup/container-builder/util"
 	"github.com/kiegroup/container-builder/util/log"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
 )
 
 type BuilderProperty string
 
 const KanikoCache BuilderProperty = "kaniko-cache"
 
+// KanikoOptions the api.KanikoOptions of the Kaniko task
+const KanikoOptions BuilderProperty = "kaniko-options"
+
+// buildCancelledError the error set when the build is cancelled
+const buildCancelledError = "Build cancelled"
+
 type BuilderInfo struct {
 	FinalImageName  string
 	BuildUniqueName string
@@ -25,16 +39,40 @@ type resource struct {
 	Content []byte
 }
 
-type buildContext struct {
+// BuildContext the state shared by the schedulers and the actions of a build
+type BuildContext struct {
 	client.Client
-	C         context.Context
-	Build     *api.Build
-	BaseImage string
+	C          context.Context
+	Build      *api.Build
+	BaseImage  string
+	Decorators []ObjectDecorator
+	// Recorder emits the build events when set, attached to EventObject or to the builder Pod when it's nil
+	Recorder    record.EventRecorder
+	EventObject runtime.Object
+	// TracerProvider provides the tracer of the spans of the actions, the global one is used when it's nil
+	TracerProvider trace.TracerProvider
+	actions        []customAction
+}
+
+// customActions returns the custom actions registered for the given phase and stage, in registration order
+func (c *BuildContext) customActions(phase api.BuildPhase, stage ActionStage) []Action {
+	var actions []Action
+	for _, a := range c.actions {
+		if a.phase == phase && a.stage == stage {
+			actions = append(actions, a.action)
+		}
+	}
+	return actions
+}
+
+// DecoratedClient the client that must be used to create objects, so they can be decorated by client code
+func (c *BuildContext) DecoratedClient() client.Client {
+	return newDecoratorClient(c.Client, c.Decorators)
 }
 
 type builder struct {
 	L       log.Logger
-	Context buildContext
+	Context BuildContext
 }
 
 type scheduler struct {
@@ -46,11 +84,6 @@ type scheduler struct {
 var _ Scheduler = &scheduler{}
 var _ Builder = &builder{}
 
-// available schedulers, add them in priority order
-var schedulers = map[string]schedulerHandler{
-	"kaniko": &kanikoSchedulerHandler{},
-}
-
 // Scheduler provides an interface to add resources and schedule a new build
 type Scheduler interface {
 	// WithResource the actual file/resource to add to the builder. Might be called multiple times.
@@ -60,45 +93,97 @@ type Scheduler interface {
 	WithResourceRequirements(res corev1.ResourceRequirements) Scheduler
 	// WithAdditionalArgs array of strings to pass to the underlying builder. For example "--myarg=myvalue" or "MY_ENV=MY_VALUE". The args are passed separated by spaces.
 	WithAdditionalArgs(args []string) Scheduler
+	// WithBuildArgs values of the Dockerfile ARG instructions, literal or read from ConfigMaps and Secrets. Might be called multiple times.
+	// Prefer them to "--build-arg" additional args, the values read from Secrets are never written in the builder Pod.
+	WithBuildArgs(args []api.BuildArg) Scheduler
+	// WithBuildSecrets Secrets mounted in the builder for the Dockerfile to read their files, see api.BuildSecret. Might be called multiple times.
+	WithBuildSecrets(secrets []api.BuildSecret) Scheduler
 	// WithProperty specialized property known by inner implementations for additional properties to configure the underlying builder
 	WithProperty(property BuilderProperty, object interface{}) Scheduler
-	Schedule() (*api.Build, error)
+	// WithObjectDecorator decorator called for every object created while scheduling the build. Might be called multiple times.
+	WithObjectDecorator(decorator ObjectDecorator) Scheduler
+	// WithAction custom action invoked for the builds in the given phase at the given stage, see ActionStage. Might be called multiple times.
+	WithAction(phase api.BuildPhase, stage ActionStage, action Action) Scheduler
+	// WithEventRecorder recorder of the build events, attached to the given object or to the builder Pod if nil.
+	WithEventRecorder(recorder record.EventRecorder, object runtime.Object) Scheduler
+	// WithTracerProvider provider of the tracer of the spans of every action invoked for the build, see NewTracerProvider.
+	WithTracerProvider(provider trace.TracerProvider) Scheduler
+	// Validate returns the errors found in the build to schedule, reported with the path of the offending fields.
+	Validate(ctx context.Context) error
+	// Schedule creates the build, the given context is used by every call to the cluster.
+	Schedule(ctx context.Context) (*api.Build, error)
 }
 
 type Builder interface {
 	WithClient(client client.Client) Builder
-	CancelBuild() (*api.Build, error)
-	Reconcile() (*api.Build, error)
+	// WithObjectDecorator decorator called for every object created while reconciling the build, like the builder Pod. Might be called multiple times.
+	WithObjectDecorator(decorator ObjectDecorator) Builder
+	// WithAction custom action invoked for the builds in the given phase at the given stage, see ActionStage. Might be called multiple times.
+	WithAction(phase api.BuildPhase, stage ActionStage, action Action) Builder
+	// WithEventRecorder recorder of the build events, like the phase transitions, attached to the given object or to the builder Pod if nil.
+	WithEventRecorder(recorder record.EventRecorder, object runtime.Object) Builder
+	// WithTracerProvider provider of the tracer of the spans of every action invoked for the build, linked by the trace ID recorded in the build status.
+	WithTracerProvider(provider trace.TracerProvider) Builder
+	// CancelBuild interrupts the build, deleting the builder Pod.
+	CancelBuild(ctx context.Context) (*api.Build, error)
+	// Reconcile updates the build status, the given context is used by every call to the cluster.
+	// The reconciliation stops with an error as soon as the context is done.
+	Reconcile(ctx context.Context) (*api.Build, error)
+	// Logs streams the logs of the builder Pod, following them until the Pod terminates if required or the context is done.
+	// The secret values are masked, like the values of the secret build arguments expanded by the builder output.
+	Logs(ctx context.Context, follow bool) (io.ReadCloser, error)
+	// Watch reconciles the build upon every builder Pod event until it's finished, sending every updated build to the returned channel and to the optional callback.
+	// The callback gets the reconcile errors too, the watch stopping once the reconciliation keeps failing.
+	Watch(ctx context.Context, callback BuildCallback) (<-chan *api.Build, error)
+	// WaitForCompletion watches the build until it's finished or the context is done.
+	WaitForCompletion(ctx context.Context) (*api.Build, error)
 }
 
-type schedulerHandler interface {
-	CreateScheduler(info BuilderInfo, buildCtx buildContext) Scheduler
+// SchedulerHandler creates the Scheduler of the builds it can handle. See RegisterSchedulerHandler.
+type SchedulerHandler interface {
+	// CreateScheduler creates the Scheduler of a new build, the given context holds the client and the decorators set by the caller.
+	CreateScheduler(info BuilderInfo, buildCtx BuildContext) Scheduler
+	// CanHandle returns true if the handler supports the build, usually depending on the PlatformBuild strategies.
 	CanHandle(info BuilderInfo) bool
 }
 
 func FromBuild(build *api.Build) Builder {
 	return &builder{
 		L: log.WithName(util.ComponentName),
-		Context: buildContext{
+		Context: BuildContext{
 			Build: build,
-			C:     context.TODO(),
 		},
 	}
 }
 
 // NewBuild is the API entry for the Builder. Create a new Build instance based on PlatformBuild.
-func NewBuild(info BuilderInfo) Scheduler {
-	ctx := buildContext{
+// The Scheduler is created by the registered SchedulerHandler with the highest priority able to handle the build.
+// An error is returned if the BuilderInfo is not valid or if no builder supports the PlatformBuild strategies.
+func NewBuild(info BuilderInfo) (Scheduler, error) {
+	if errs := info.Validate(); len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+	buildCtx := BuildContext{
 		BaseImage: info.Platform.Spec.BaseImage,
-		C:         context.TODO(),
 	}
 
-	for _, v := range schedulers {
-		if v.CanHandle(info) {
-			return v.CreateScheduler(info, ctx)
-		}
+	if handler := findSchedulerHandler(info); handler != nil {
+		return handler.CreateScheduler(info, buildCtx), nil
 	}
-	panic(fmt.Errorf("BuildStrategy %s with PublishStrategy %s is not supported", info.Platform.Spec.BuildStrategy, info.Platform.Spec.PublishStrategy))
+	return nil, field.Invalid(field.NewPath("platform", "spec", "publishStrategy"), info.Platform.Spec.PublishStrategy,
+		fmt.Sprintf("not supported with build strategy %s", info.Platform.Spec.BuildStrategy))
+}
+
+// Validate returns the errors found in the BuilderInfo, reported with the path of the offending fields
+func (info BuilderInfo) Validate() field.ErrorList {
+	var errs field.ErrorList
+	if len(info.FinalImageName) == 0 {
+		errs = append(errs, field.Required(field.NewPath("finalImageName"), ""))
+	}
+	if len(info.BuildUniqueName) == 0 {
+		errs = append(errs, field.Required(field.NewPath("buildUniqueName"), ""))
+	}
+	return append(errs, info.Platform.Spec.Validate(field.NewPath("platform", "spec"))...)
 }
 
 func (s *scheduler) WithClient(client client.Client) Scheduler {
@@ -121,18 +206,85 @@ func (s *scheduler) WithAdditionalArgs(args []string) Scheduler {
 	return s.Scheduler
 }
 
+func (s *scheduler) WithBuildArgs(args []api.BuildArg) Scheduler {
+	// no default implementation.
+	return s.Scheduler
+}
+
+func (s *scheduler) WithBuildSecrets(secrets []api.BuildSecret) Scheduler {
+	// no default implementation.
+	return s.Scheduler
+}
+
 func (s *scheduler) WithProperty(property BuilderProperty, object interface{}) Scheduler {
 	// no default implementation
 	return s.Scheduler
 }
 
+func (s *scheduler) WithObjectDecorator(decorator ObjectDecorator) Scheduler {
+	s.builder.WithObjectDecorator(decorator)
+	return s.Scheduler
+}
+
+func (s *scheduler) WithAction(phase api.BuildPhase, stage ActionStage, action Action) Scheduler {
+	s.builder.WithAction(phase, stage, action)
+	return s.Scheduler
+}
+
+func (s *scheduler) WithEventRecorder(recorder record.EventRecorder, object runtime.Object) Scheduler {
+	s.builder.WithEventRecorder(recorder, object)
+	return s.Scheduler
+}
+
+func (s *scheduler) WithTracerProvider(provider trace.TracerProvider) Scheduler {
+	s.builder.WithTracerProvider(provider)
+	return s.Scheduler
+}
+
+// Validate checks the build to schedule. The Secrets and ConfigMaps it references must exist when a client is set.
+func (s *scheduler) Validate(ctx context.Context) error {
+	build := s.builder.Context.Build
+	errs := build.Validate()
+	optionErrs, warnings := validateKanikoOptions(build, kanikoVersion(build))
+	errs = append(errs, optionErrs...)
+	errs = append(errs, validateSecretBuildArgs(build)...)
+	for _, warning := range warnings {
+		s.builder.L.Warn("the Kaniko additional flag may not be supported", "build", build.Name, "reason", warning)
+	}
+	if s.builder.Context.Client != nil {
+		errs = append(errs, validateSecrets(ctx, s.builder.Context.Client, build)...)
+		errs = append(errs, validateBuildArgsAndSecrets(ctx, s.builder.Context.Client, build)...)
+	}
+	return errs.ToAggregate()
+}
+
 // Schedule schedules a new build in the platform
-func (s *scheduler) Schedule() (*api.Build, error) {
+func (s *scheduler) Schedule(ctx context.Context) (*api.Build, error) {
+	if err := contextError(ctx, "scheduling", s.builder.Context.Build); err != nil {
+		return nil, err
+	}
+	s.builder.Context.C = ctx
+	if err := s.resolveRegistryAddress(ctx); err != nil {
+		return nil, err
+	}
+	s.resolveKanikoVersion(ctx)
+	if err := s.Validate(ctx); err != nil {
+		return nil, errors.Wrapf(err, "invalid build %s", s.builder.Context.Build.Name)
+	}
+	if err := s.pinBaseImages(ctx); err != nil {
+		return nil, errors.Wrapf(err, "cannot pin the base images of build %s", s.builder.Context.Build.Name)
+	}
 	// TODO: create a handler to mount the resources according to the platform/context options (for now we only have CM, PoC level)
 	if err := mountResourcesWithConfigMap(&s.builder.Context, &s.Resources); err != nil {
 		return nil, err
 	}
-	return s.builder.Reconcile()
+	for _, r := range s.Resources {
+		if r.Target == dockerfileName {
+			s.builder.Context.Build.Status.Progress = &api.BuildProgress{TotalSteps: countDockerfileSteps(r.Content)}
+		}
+	}
+	s.setInputHash(ctx)
+	return s.builder.Reconcile(ctx)
 }
 
 func (b *builder) WithClient(client client.Client) Builder {
@@ -140,9 +292,36 @@ func (b *builder) WithClient(client client.Client) Builder {
 	return b
 }
 
+func (b *builder) WithObjectDecorator(decorator ObjectDecorator) Builder {
+	b.Context.Decorators = append(b.Context.Decorators, decorator)
+	return b
+}
+
+func (b *builder) WithAction(phase api.BuildPhase, stage ActionStage, action Action) Builder {
+	b.Context.actions = append(b.Context.actions, customAction{phase: phase, stage: stage, action: action})
+	return b
+}
+
+func (b *builder) WithEventRecorder(recorder record.EventRecorder, object runtime.Object) Builder {
+	b.Context.Recorder = recorder
+	b.Context.EventObject = object
+	return b
+}
+
+func (b *builder) WithTracerProvider(provider trace.TracerProvider) Builder {
+	b.Context.TracerProvider = provider
+	return b
+}
+
 // Reconcile idempotent build flow control.
 // Can be called many times to check/update the current status of the build instance, indexed by the Platform and Build Name.
-func (b *builder) Reconcile() (*api.Build, error) {
+func (b *builder) Reconcile(ctx context.Context) (*api.Build, error) {
+	if err := contextError(ctx, "reconciliation", b.Context.Build); err != nil {
+		return nil, err
+	}
+	b.Context.C = ctx
+	b.L = b.L.WithRedactor(buildRedactor(b.Context.Build))
+
 	var actions []Action
 	switch b.Context.Build.Spec.Strategy {
 	case api.BuildStrategyPod:
@@ -151,43 +330,169 @@ func (b *builder) Reconcile() (*api.Build, error) {
 			newInitializePodAction(),
 			newScheduleAction(),
 			newMonitorPodAction(),
+			newMonitorJobAction(),
 			newErrorRecoveryAction(),
 		}
 	}
 
 	target := b.Context.Build.DeepCopy()
+	phase := target.Status.Phase
 
-	for _, a := range actions {
-		a.InjectLogger(b.L)
-		a.InjectClient(b.Context.Client)
+	var err error
 
-		if a.CanHandle(target) {
-			b.L.Infof("Invoking action %s", a.Name())
-			newTarget, err := a.Handle(b.Context.C, target)
-			if err != nil {
-				b.L.Errorf(err, "Failed to invoke action %s", a.Name())
+	for _, a := range b.Context.customActions(phase, ActionStageBefore) {
+		if b.canHandle(a, target) {
+			if target, err = b.handle(a, target); err != nil {
 				return nil, err
 			}
+			if target.Status.Phase != phase {
+				break
+			}
+		}
+	}
 
-			if newTarget != nil {
-				if newTarget.Status.Phase != target.Status.Phase {
-					b.L.Info(
-						"state transition",
-						"phase-from", target.Status.Phase,
-						"phase-to", newTarget.Status.Phase,
-					)
+	if target.Status.Phase == phase {
+		// custom actions take precedence over the built-in ones
+		actions = append(b.Context.customActions(phase, ActionStageHandle), actions...)
+		for _, a := range actions {
+			if b.canHandle(a, target) {
+				if target, err = b.handle(a, target); err != nil {
+					return nil, err
 				}
+				break
+			}
+		}
+	}
 
-				target = newTarget
+	if target.Status.Phase != phase && target.Status.Phase.IsFinished() && b.Context.Client != nil {
+		if err := deleteBuildArgsSecret(ctx, b.Context.Client, target); err != nil {
+			b.L.Errorf(err, "Cannot delete the build arguments Secret of build %s", target.Name)
+		}
+	}
+
+	if target.Status.Phase != phase {
+		// the build already entered the phase, so the failures of the after actions are only logged by handle:
+		// invoking them again would transition the build again
+		for _, a := range b.Context.customActions(target.Status.Phase, ActionStageAfter) {
+			if b.canHandle(a, target) {
+				if newTarget, err := b.handle(a, target); err == nil {
+					target = newTarget
+				}
 			}
-			break
 		}
 	}
 
 	return target, nil
 }
 
-func (b *builder) CancelBuild() (*api.Build, error) {
-	//TODO implement me
-	panic("implement me")
+// canHandle injects the logger and the client into the action, then checks if it can handle the build
+func (b *builder) canHandle(a Action, target *api.Build) bool {
+	a.InjectLogger(b.L)
+	a.InjectClient(b.Context.DecoratedClient())
+	return a.CanHandle(target)
+}
+
+// handle invokes the action, returning the updated build.
+// The action works on a copy of the build, so its changes are discarded if it returns no build.
+func (b *builder) handle(a Action, target *api.Build) (*api.Build, error) {
+	if err := contextError(b.Context.C, "reconciliation", target); err != nil {
+		return nil, err
+	}
+	b.L.Infof("Invoking action %s", a.Name())
+	ctx, span := b.startActionSpan(a, target)
+	newTarget, err := a.Handle(ctx, target.DeepCopy())
+	// the secret values are masked before they're logged, stored or sent to events
+	redactor := buildRedactor(target)
+	err = redactor.RedactError(err)
+	if newTarget != nil {
+		redactStatus(redactor, newTarget)
+	}
+	endActionSpan(span, newTarget, err)
+	if err != nil {
+		b.L.Errorf(err, "Failed to invoke action %s", a.Name())
+		return nil, err
+	}
+	if newTarget == nil {
+		return target, nil
+	}
+	if newTarget.Status.Phase != target.Status.Phase {
+		if err := transition(target.Status.Phase, newTarget, a.Name()); err != nil {
+			b.L.Errorf(err, "Action %s failed", a.Name())
+			return nil, err
+		}
+		b.L.Info(
+			"state transition",
+			"phase-from", target.Status.Phase,
+			"phase-to", newTarget.Status.Phase,
+		)
+		b.recordTransitionEvents(b.Context.C, target.Status.Phase, newTarget)
+		recordTransitionMetrics(target.Status.Phase, newTarget)
+	}
+	return newTarget, nil
+}
+
+// contextError returns an error telling the operation stopped if the context is done
+func contextError(ctx context.Context, operation string, build *api.Build) error {
+	if err := ctx.Err(); err != nil {
+		return errors.Wrapf(err, "%s of build %s stopped", operation, build.Name)
+	}
+	return nil
+}
+
+// transition checks the build can move from the given phase to its current one, recording the transition in its status
+func transition(from api.BuildPhase, build *api.Build, reason string) error {
+	to := build.Status.Phase
+	if !from.CanTransitionTo(to) {
+		return errors.Errorf("invalid phase transition of build %s from %q to %q by %s", build.Name, from, to, reason)
+	}
+	build.Status.Transitions = append(build.Status.Transitions, api.BuildPhaseTransition{
+		From:   from,
+		To:     to,
+		Time:   metav1.Now(),
+		Reason: reason,
+	})
+	return nil
+}
+
+// CancelBuild stops the build deleting the builder Pod, if any. Finished builds are left untouched.
+func (b *builder) CancelBuild(ctx context.Context) (*api.Build, error) {
+	build := b.Context.Build.DeepCopy()
+	if build.Status.Phase.IsFinished() {
+		return build, nil
+	}
+	// the event is attached to the builder Pod before deleting it
+	if b.Context.Recorder != nil {
+		cancelled := build.DeepCopy()
+		cancelled.Status.Phase = api.BuildPhaseInterrupted
+		cancelled.Status.Error = buildCancelledError
+		b.recordTransitionEvents(ctx, build.Status.Phase, cancelled)
+	}
+	if err := deleteBuilderPod(ctx, b.Context.Client, build); err != nil {
+		return nil, errors.Wrap(err, "cannot delete build pod")
+	}
+	from := build.Status.Phase
+	build.Status.Phase = api.BuildPhaseInterrupted
+	build.Status.Error = buildCancelledError
+	if err := transition(from, build, "cancel"); err != nil {
+		return nil, err
+	}
+	recordTransitionMetrics(from, build)
+	return build, nil
+}
+
+// Logs streams the logs of the builder Pod.
+func (b *builder) Logs(ctx context.Context, follow bool) (io.ReadCloser, error) {
+	pod, err := getBuilderPod(ctx, b.Context.Client, b.Context.Build)
+	if err != nil {
+		return nil, err
+	}
+	if pod == nil {
+		return nil, errors.Errorf("no builder pod found for build %s in namespace %s", b.Context.Build.Name, b.Context.Build.Namespace)
+	}
+	logs, err := b.Context.Client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Follow: follow}).Stream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// the builder output expands the build arguments
+	return buildRedactor(b.Context.Build).RedactReader(logs), nil
 }
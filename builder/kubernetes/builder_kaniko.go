@@ -1,6 +1,7 @@
 package kubernetes
 
 import (
+	"context"
 	"path"
 
 	"github.com/kiegroup/container-builder/api"
@@ -17,9 +18,9 @@ type kanikoScheduler struct {
 type kanikoSchedulerHandler struct {
 }
 
-var _ schedulerHandler = &kanikoSchedulerHandler{}
+var _ SchedulerHandler = &kanikoSchedulerHandler{}
 
-func (k kanikoSchedulerHandler) CreateScheduler(info BuilderInfo, buildCtx buildContext) Scheduler {
+func (k kanikoSchedulerHandler) CreateScheduler(info BuilderInfo, buildCtx BuildContext) Scheduler {
 	kanikoTask := api.KanikoTask{
 		BaseTask: api.BaseTask{Name: "KanikoTask"},
 		PublishTask: api.PublishTask{
@@ -28,19 +29,29 @@ func (k kanikoSchedulerHandler) CreateScheduler(info BuilderInfo, buildCtx build
 			Image:      info.FinalImageName,
 			Registry:   info.Platform.Spec.Registry,
 		},
-		Cache: api.KanikoTaskCache{},
+		Cache:         api.KanikoTaskCache{},
+		ExecutorImage: info.Platform.Spec.GetExecutorImage(),
 	}
 
 	buildCtx.Build = &api.Build{
 		Spec: api.BuildSpec{
 			Tasks:    []api.Task{{Kaniko: &kanikoTask}},
 			Strategy: api.BuildStrategyPod,
-			Timeout:  *info.Platform.Spec.Timeout,
+			Timeout:  info.Platform.Spec.GetTimeout(),
+			// the profile is resolved here to keep it even if the platform default changes
+			SecurityProfile: info.Platform.Spec.GetSecurityProfile(),
+			Job:             info.Platform.Spec.Job.DeepCopy(),
+			InputCache:      info.Platform.Spec.InputCache,
+			PinBaseImages:   info.Platform.Spec.PinBaseImages,
 		},
 	}
 	buildCtx.Build.Name = info.BuildUniqueName
 	buildCtx.Build.Namespace = info.Platform.Namespace
+	return newKanikoScheduler(buildCtx, &kanikoTask)
+}
 
+// newKanikoScheduler creates the Scheduler of the build held by the context, running the given Kaniko task of the build
+func newKanikoScheduler(buildCtx BuildContext, kanikoTask *api.KanikoTask) *kanikoScheduler {
 	sched := &kanikoScheduler{
 		&scheduler{
 			builder: builder{
@@ -49,10 +60,14 @@ func (k kanikoSchedulerHandler) CreateScheduler(info BuilderInfo, buildCtx build
 			},
 			Resources: make([]resource, 0),
 		},
-		&kanikoTask,
+		kanikoTask,
 	}
 	// we hold our own reference for the default methods to return the right object
 	sched.Scheduler = sched
+
+	if reason, ok := kanikoIncompatibleProfiles[buildCtx.Build.Spec.SecurityProfile]; ok {
+		sched.builder.L.Warn("security profile not compatible with the Kaniko builder", "profile", buildCtx.Build.Spec.SecurityProfile, "reason", reason)
+	}
 	return sched
 }
 
@@ -64,6 +79,9 @@ func (sk *kanikoScheduler) WithProperty(property BuilderProperty, object interfa
 	if property == KanikoCache {
 		sk.KanikoTask.Cache = object.(api.KanikoTaskCache)
 	}
+	if property == KanikoOptions {
+		sk.KanikoTask.Options = object.(api.KanikoOptions)
+	}
 	return sk
 }
 
@@ -77,7 +95,17 @@ func (sk *kanikoScheduler) WithAdditionalArgs(flags []string) Scheduler {
 	return sk
 }
 
-func (sk *kanikoScheduler) Schedule() (*api.Build, error) {
+func (sk *kanikoScheduler) WithBuildArgs(args []api.BuildArg) Scheduler {
+	sk.KanikoTask.BuildArgs = append(sk.KanikoTask.BuildArgs, args...)
+	return sk
+}
+
+func (sk *kanikoScheduler) WithBuildSecrets(secrets []api.BuildSecret) Scheduler {
+	sk.KanikoTask.BuildSecrets = append(sk.KanikoTask.BuildSecrets, secrets...)
+	return sk
+}
+
+func (sk *kanikoScheduler) Schedule(ctx context.Context) (*api.Build, error) {
 	// verify if we really need this
 	for _, task := range sk.builder.Context.Build.Spec.Tasks {
 		if task.Kaniko != nil {
@@ -85,5 +113,5 @@ func (sk *kanikoScheduler) Schedule() (*api.Build, error) {
 			break
 		}
 	}
-	return sk.scheduler.Schedule()
+	return sk.scheduler.Schedule(ctx)
 }
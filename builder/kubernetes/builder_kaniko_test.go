@@ -49,7 +49,9 @@ func TestNewBuildWithKanikoCustomizations(t *testing.T) {
 	addFlags[0] = "--use-new-run=true"
 
 	// create the new build, schedule with cache enabled, a specific set of resources and additional flags
-	build, err := NewBuild(BuilderInfo{FinalImageName: "quay.io/kiegroup/buildexample:latest", BuildUniqueName: "build1", Platform: platform}).
+	scheduler, err := NewBuild(BuilderInfo{FinalImageName: "quay.io/kiegroup/buildexample:latest", BuildUniqueName: "build1", Platform: platform})
+	assert.NoError(t, err)
+	build, err := scheduler.
 		WithProperty(KanikoCache, api.KanikoTaskCache{Enabled: util.Pbool(true), PersistentVolumeClaim: "kaniko-cache-pv"}).
 		WithResourceRequirements(v1.ResourceRequirements{
 			Limits: v1.ResourceList{
@@ -65,19 +67,19 @@ func TestNewBuildWithKanikoCustomizations(t *testing.T) {
 		WithResource("Dockerfile", dockerFile).
 		WithResource("greetings.sw.json", workflowDefinition).
 		WithClient(c).
-		Schedule()
+		Schedule(context.TODO())
 
 	assert.NoError(t, err)
 	assert.NotNil(t, build)
 	assert.Equal(t, api.BuildPhaseScheduling, build.Status.Phase)
 
-	build, err = FromBuild(build).WithClient(c).Reconcile()
+	build, err = FromBuild(build).WithClient(c).Reconcile(context.TODO())
 	assert.NoError(t, err)
 	assert.NotNil(t, build)
 	assert.Equal(t, api.BuildPhasePending, build.Status.Phase)
 
 	// The status won't change since FakeClient won't set the status upon creation, since we don't have a controller :)
-	build, err = FromBuild(build).WithClient(c).Reconcile()
+	build, err = FromBuild(build).WithClient(c).Reconcile(context.TODO())
 	assert.NoError(t, err)
 	assert.NotNil(t, build)
 	assert.Equal(t, api.BuildPhasePending, build.Status.Phase)
@@ -91,3 +93,62 @@ func TestNewBuildWithKanikoCustomizations(t *testing.T) {
 
 	assert.Subset(t, pod.Spec.Containers[0].Args, addFlags)
 }
+
+func TestNewBuildWithKanikoSecurityProfiles(t *testing.T) {
+	ns := "test"
+	c, err := test.NewFakeClient()
+	assert.NoError(t, err)
+
+	dockerFile, err := os.ReadFile("testdata/Dockerfile")
+	assert.NoError(t, err)
+
+	for _, profile := range []api.SecurityProfile{"", api.SecurityProfileRestricted, api.SecurityProfilePrivileged} {
+		platform := api.PlatformBuild{
+			ObjectReference: api.ObjectReference{
+				Namespace: ns,
+				Name:      "testPlatform",
+			},
+			Spec: api.PlatformBuildSpec{
+				BuildStrategy:   api.BuildStrategyPod,
+				PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+				Timeout:         &metav1.Duration{Duration: 5 * time.Minute},
+				SecurityProfile: profile,
+			},
+		}
+
+		buildName := "build-" + string(platform.Spec.GetSecurityProfile())
+		scheduler, err := NewBuild(BuilderInfo{FinalImageName: "quay.io/kiegroup/buildexample:latest", BuildUniqueName: buildName, Platform: platform})
+		assert.NoError(t, err)
+		build, err := scheduler.
+			WithResource("Dockerfile", dockerFile).
+			WithClient(c).
+			Schedule(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, platform.Spec.GetSecurityProfile(), build.Spec.SecurityProfile)
+
+		build, err = FromBuild(build).WithClient(c).Reconcile(context.TODO())
+		assert.NoError(t, err)
+		build, err = FromBuild(build).WithClient(c).Reconcile(context.TODO())
+		assert.NoError(t, err)
+
+		pod := &v1.Pod{}
+		err = c.Get(context.TODO(), types.NamespacedName{Name: buildPodName(build), Namespace: ns}, pod)
+		assert.NoError(t, err)
+		assert.NotNil(t, pod.Spec.SecurityContext)
+		container := pod.Spec.Containers[0]
+		assert.NotNil(t, container.SecurityContext)
+
+		switch build.Spec.SecurityProfile {
+		case api.SecurityProfileBaseline:
+			assert.False(t, *container.SecurityContext.Privileged)
+			assert.Equal(t, v1.SeccompProfileTypeRuntimeDefault, pod.Spec.SecurityContext.SeccompProfile.Type)
+		case api.SecurityProfileRestricted:
+			assert.True(t, *pod.Spec.SecurityContext.RunAsNonRoot)
+			assert.Equal(t, kanikoNonRootUser, *pod.Spec.SecurityContext.RunAsUser)
+			assert.False(t, *container.SecurityContext.AllowPrivilegeEscalation)
+			assert.Equal(t, []v1.Capability{"ALL"}, container.SecurityContext.Capabilities.Drop)
+		case api.SecurityProfilePrivileged:
+			assert.True(t, *container.SecurityContext.Privileged)
+		}
+	}
+}
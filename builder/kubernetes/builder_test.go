@@ -3,6 +3,7 @@ package kubernetes
 import (
 	"context"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,9 +11,13 @@ import (
 
 	"github.com/kiegroup/container-builder/api"
 	"github.com/kiegroup/container-builder/util/test"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 )
 
 func TestNewBuild(t *testing.T) {
@@ -38,23 +43,25 @@ func TestNewBuild(t *testing.T) {
 		},
 	}
 	// create the new build, schedule
-	build, err := NewBuild(BuilderInfo{FinalImageName: "quay.io/kiegroup/buildexample:latest", BuildUniqueName: "build1", Platform: platform}).
+	scheduler, err := NewBuild(BuilderInfo{FinalImageName: "quay.io/kiegroup/buildexample:latest", BuildUniqueName: "build1", Platform: platform})
+	assert.NoError(t, err)
+	build, err := scheduler.
 		WithClient(c).
 		WithResource("Dockerfile", dockerFile).
 		WithResource("greetings.sw.json", workflowDefinition).
-		Schedule()
+		Schedule(context.TODO())
 
 	assert.NoError(t, err)
 	assert.NotNil(t, build)
 	assert.Equal(t, api.BuildPhaseScheduling, build.Status.Phase)
 
-	build, err = FromBuild(build).WithClient(c).Reconcile()
+	build, err = FromBuild(build).WithClient(c).Reconcile(context.TODO())
 	assert.NoError(t, err)
 	assert.NotNil(t, build)
 	assert.Equal(t, api.BuildPhasePending, build.Status.Phase)
 
 	// The status won't change since FakeClient won't set the status upon creation, since we don't have a controller :)
-	build, err = FromBuild(build).WithClient(c).Reconcile()
+	build, err = FromBuild(build).WithClient(c).Reconcile(context.TODO())
 	assert.NoError(t, err)
 	assert.NotNil(t, build)
 	assert.Equal(t, api.BuildPhasePending, build.Status.Phase)
@@ -66,3 +73,585 @@ func TestNewBuild(t *testing.T) {
 	assert.NotNil(t, pod)
 	assert.Len(t, pod.Spec.Volumes, 1)
 }
+
+func TestNewBuildWithObjectDecorators(t *testing.T) {
+	ns := "test"
+	c, err := test.NewFakeClient()
+	assert.NoError(t, err)
+
+	dockerFile, err := os.ReadFile("testdata/Dockerfile")
+	assert.NoError(t, err)
+
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{
+			Namespace: ns,
+			Name:      "testPlatform",
+		},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy:   api.BuildStrategyPod,
+			PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+			Timeout:         &metav1.Duration{Duration: 5 * time.Minute},
+		},
+	}
+	owner := &v1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: ns, UID: "owner-uid"},
+	}
+	labels := LabelsDecorator(map[string]string{"app": "my-operator"})
+	ownerRef := OwnerReferenceDecorator(owner, c.GetScheme())
+
+	scheduler, err := NewBuild(BuilderInfo{FinalImageName: "quay.io/kiegroup/buildexample:latest", BuildUniqueName: "build1", Platform: platform})
+	assert.NoError(t, err)
+	build, err := scheduler.
+		WithClient(c).
+		WithObjectDecorator(labels).
+		WithObjectDecorator(ownerRef).
+		WithResource("Dockerfile", dockerFile).
+		Schedule(context.TODO())
+	assert.NoError(t, err)
+
+	build, err = FromBuild(build).WithClient(c).WithObjectDecorator(labels).WithObjectDecorator(ownerRef).Reconcile(context.TODO())
+	assert.NoError(t, err)
+	build, err = FromBuild(build).WithClient(c).WithObjectDecorator(labels).WithObjectDecorator(ownerRef).Reconcile(context.TODO())
+	assert.NoError(t, err)
+
+	configMap := &v1.ConfigMap{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: build.Status.ResourceVolume.ReferenceName, Namespace: ns}, configMap)
+	assert.NoError(t, err)
+	pod := &v1.Pod{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: buildPodName(build), Namespace: ns}, pod)
+	assert.NoError(t, err)
+
+	for _, obj := range []metav1.Object{configMap, pod} {
+		assert.Equal(t, "my-operator", obj.GetLabels()["app"])
+		assert.Len(t, obj.GetOwnerReferences(), 1)
+		assert.Equal(t, owner.UID, obj.GetOwnerReferences()[0].UID)
+	}
+	// our own labels must be preserved
+	assert.Equal(t, build.Name, pod.Labels["kie.kogito.org/buildContext"])
+}
+
+func TestNewBuildValidation(t *testing.T) {
+	ns := "test"
+	c, err := test.NewFakeClient()
+	assert.NoError(t, err)
+
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{
+			Namespace: ns,
+			Name:      "testPlatform",
+		},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy:   api.BuildStrategyRoutine,
+			PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+			Timeout:         &metav1.Duration{Duration: -5 * time.Minute},
+		},
+	}
+	_, err = NewBuild(BuilderInfo{BuildUniqueName: "build1", Platform: platform})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "finalImageName: Required value")
+	assert.Contains(t, err.Error(), "platform.spec.buildStrategy: Invalid value")
+	assert.Contains(t, err.Error(), "platform.spec.timeout: Invalid value")
+
+	// the default timeout is used when none is set
+	platform.Spec.BuildStrategy = api.BuildStrategyPod
+	platform.Spec.Timeout = nil
+	platform.Spec.Registry.Secret = "missing-secret"
+	scheduler, err := NewBuild(BuilderInfo{FinalImageName: "quay.io/kiegroup/Build:latest", BuildUniqueName: "build1", Platform: platform})
+	assert.NoError(t, err)
+	_, err = scheduler.
+		WithClient(c).
+		WithAdditionalArgs([]string{"--cache=true", "--destination=quay.io/kiegroup/other:latest"}).
+		Schedule(context.TODO())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.tasks[0].kaniko.image: Invalid value")
+	assert.Contains(t, err.Error(), "spec.tasks[0].kaniko.additionalFlags[1]: Invalid value")
+	assert.Contains(t, err.Error(), "spec.tasks[0].kaniko.registry.secret: Not found")
+	assert.NotContains(t, err.Error(), "additionalFlags[0]")
+
+	// nothing has been created
+	pods := &v1.PodList{}
+	assert.NoError(t, c.List(context.TODO(), pods))
+	assert.Empty(t, pods.Items)
+}
+
+type testPolicyAction struct {
+	BaseAction
+	allowedRegistry string
+}
+
+func (a *testPolicyAction) Name() string {
+	return "test-policy"
+}
+
+func (a *testPolicyAction) CanHandle(build *api.Build) bool {
+	return a.Client() != nil
+}
+
+func (a *testPolicyAction) Handle(ctx context.Context, build *api.Build) (*api.Build, error) {
+	if !strings.HasPrefix(build.Spec.Tasks[0].Kaniko.Image, a.allowedRegistry) {
+		build.Status.Phase = api.BuildPhaseError
+		build.Status.Error = "image not allowed by the registry policy"
+	}
+	return build, nil
+}
+
+type testNotificationAction struct {
+	BaseAction
+	notified []api.BuildPhase
+}
+
+func (a *testNotificationAction) Name() string {
+	return "test-notification"
+}
+
+func (a *testNotificationAction) CanHandle(build *api.Build) bool {
+	return true
+}
+
+func (a *testNotificationAction) Handle(ctx context.Context, build *api.Build) (*api.Build, error) {
+	a.notified = append(a.notified, build.Status.Phase)
+	return nil, nil
+}
+
+type testUnavailableAction struct {
+	BaseAction
+}
+
+func (a *testUnavailableAction) Name() string {
+	return "test-unavailable"
+}
+
+func (a *testUnavailableAction) CanHandle(build *api.Build) bool {
+	return true
+}
+
+func (a *testUnavailableAction) Handle(ctx context.Context, build *api.Build) (*api.Build, error) {
+	return nil, errors.New("notification service unavailable")
+}
+
+func TestNewBuildWithCustomActions(t *testing.T) {
+	ns := "test"
+	c, err := test.NewFakeClient()
+	assert.NoError(t, err)
+
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{
+			Namespace: ns,
+			Name:      "testPlatform",
+		},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy:   api.BuildStrategyPod,
+			PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+			Timeout:         &metav1.Duration{Duration: 5 * time.Minute},
+		},
+	}
+	policy := &testPolicyAction{allowedRegistry: "quay.io/kiegroup/"}
+	notification := &testNotificationAction{}
+
+	for _, buildName := range []string{"allowed", "denied"} {
+		image := "quay.io/kiegroup/buildexample:latest"
+		if buildName == "denied" {
+			image = "docker.io/library/buildexample:latest"
+		}
+		scheduler, err := NewBuild(BuilderInfo{FinalImageName: image, BuildUniqueName: buildName, Platform: platform})
+		assert.NoError(t, err)
+		build, err := scheduler.WithClient(c).Schedule(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, api.BuildPhaseScheduling, build.Status.Phase)
+
+		for i := 0; i < 2; i++ {
+			build, err = FromBuild(build).
+				WithClient(c).
+				WithAction(api.BuildPhaseScheduling, ActionStageBefore, policy).
+				WithAction(api.BuildPhasePending, ActionStageAfter, &testUnavailableAction{}).
+				WithAction(api.BuildPhasePending, ActionStageAfter, notification).
+				WithAction(api.BuildPhaseError, ActionStageAfter, notification).
+				Reconcile(context.TODO())
+			assert.NoError(t, err)
+		}
+
+		pod := &v1.Pod{}
+		err = c.Get(context.TODO(), types.NamespacedName{Name: buildPodName(build), Namespace: ns}, pod)
+		if buildName == "allowed" {
+			assert.NoError(t, err)
+			assert.Equal(t, api.BuildPhasePending, build.Status.Phase)
+			// the failing after action doesn't lose the transition, which isn't recorded again
+			pending := 0
+			for _, transition := range build.Status.Transitions {
+				if transition.To == api.BuildPhasePending {
+					pending++
+				}
+			}
+			assert.Equal(t, 1, pending)
+		} else {
+			assert.True(t, k8serrors.IsNotFound(err))
+			assert.Equal(t, api.BuildPhaseError, build.Status.Phase)
+			assert.Equal(t, "image not allowed by the registry policy", build.Status.Error)
+		}
+	}
+	// the after actions are invoked only upon the phase transitions
+	assert.Equal(t, []api.BuildPhase{api.BuildPhasePending, api.BuildPhaseError}, notification.notified)
+}
+
+type testStalePodAction struct {
+	BaseAction
+}
+
+func (a *testStalePodAction) Name() string {
+	return "test-stale-pod"
+}
+
+func (a *testStalePodAction) CanHandle(build *api.Build) bool {
+	return true
+}
+
+func (a *testStalePodAction) Handle(ctx context.Context, build *api.Build) (*api.Build, error) {
+	build.Status.Phase = api.BuildPhaseRunning
+	return build, nil
+}
+
+func TestBuildPhaseTransitions(t *testing.T) {
+	ns := "test"
+	c, err := test.NewFakeClient()
+	assert.NoError(t, err)
+
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{
+			Namespace: ns,
+			Name:      "testPlatform",
+		},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy:   api.BuildStrategyPod,
+			PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+			Timeout:         &metav1.Duration{Duration: 5 * time.Minute},
+		},
+	}
+	scheduler, err := NewBuild(BuilderInfo{FinalImageName: "quay.io/kiegroup/buildexample:latest", BuildUniqueName: "build1", Platform: platform})
+	assert.NoError(t, err)
+	build, err := scheduler.WithClient(c).Schedule(context.TODO())
+	assert.NoError(t, err)
+	build, err = FromBuild(build).WithClient(c).Reconcile(context.TODO())
+	assert.NoError(t, err)
+	build, err = FromBuild(build).WithClient(c).CancelBuild(context.TODO())
+	assert.NoError(t, err)
+
+	assert.Len(t, build.Status.Transitions, 3)
+	for i, expected := range []struct {
+		from, to api.BuildPhase
+		reason   string
+	}{
+		{api.BuildPhaseNone, api.BuildPhaseScheduling, "initialize-pod"},
+		{api.BuildPhaseScheduling, api.BuildPhasePending, "schedule"},
+		{api.BuildPhasePending, api.BuildPhaseInterrupted, "cancel"},
+	} {
+		assert.Equal(t, expected.from, build.Status.Transitions[i].From)
+		assert.Equal(t, expected.to, build.Status.Transitions[i].To)
+		assert.Equal(t, expected.reason, build.Status.Transitions[i].Reason)
+		assert.False(t, build.Status.Transitions[i].Time.IsZero())
+	}
+
+	// a finished build can't move anymore
+	_, err = FromBuild(build).WithClient(c).WithAction(api.BuildPhaseInterrupted, ActionStageHandle, &testStalePodAction{}).Reconcile(context.TODO())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid phase transition of build build1 from "Interrupted" to "Running" by test-stale-pod`)
+}
+
+func TestReconcileWithCancelledContext(t *testing.T) {
+	ns := "test"
+	c, err := test.NewFakeClient()
+	assert.NoError(t, err)
+
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{
+			Namespace: ns,
+			Name:      "testPlatform",
+		},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy:   api.BuildStrategyPod,
+			PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+			Timeout:         &metav1.Duration{Duration: 5 * time.Minute},
+		},
+	}
+	scheduler, err := NewBuild(BuilderInfo{FinalImageName: "quay.io/kiegroup/buildexample:latest", BuildUniqueName: "build1", Platform: platform})
+	assert.NoError(t, err)
+	build, err := scheduler.WithClient(c).Schedule(context.TODO())
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	_, err = FromBuild(build).WithClient(c).Reconcile(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Contains(t, err.Error(), "reconciliation of build build1 stopped")
+
+	_, err = scheduler.Schedule(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Contains(t, err.Error(), "scheduling of build build1 stopped")
+}
+
+func TestNewBuildWithJob(t *testing.T) {
+	ns := "test"
+	c, err := test.NewFakeClient()
+	assert.NoError(t, err)
+
+	dockerFile, err := os.ReadFile("testdata/Dockerfile")
+	assert.NoError(t, err)
+
+	ttl := int32(600)
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{
+			Namespace: ns,
+			Name:      "testPlatform",
+		},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy:   api.BuildStrategyPod,
+			PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+			Timeout:         &metav1.Duration{Duration: 5 * time.Minute},
+			Job:             &api.BuildJobSpec{TTLSecondsAfterFinished: &ttl},
+		},
+	}
+
+	scheduler, err := NewBuild(BuilderInfo{FinalImageName: "quay.io/kiegroup/buildexample:latest", BuildUniqueName: "build1", Platform: platform})
+	assert.NoError(t, err)
+	build, err := scheduler.
+		WithClient(c).
+		WithResource("Dockerfile", dockerFile).
+		Schedule(context.TODO())
+	assert.NoError(t, err)
+	assert.NotNil(t, build.Spec.Job)
+
+	build, err = FromBuild(build).WithClient(c).Reconcile(context.TODO())
+	assert.NoError(t, err)
+	build, err = FromBuild(build).WithClient(c).Reconcile(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, api.BuildPhasePending, build.Status.Phase)
+
+	job := &batchv1.Job{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: buildJobName(build), Namespace: ns}, job)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), *job.Spec.BackoffLimit)
+	assert.Equal(t, int64(300), *job.Spec.ActiveDeadlineSeconds)
+	assert.Equal(t, ttl, *job.Spec.TTLSecondsAfterFinished)
+	assert.Len(t, job.Spec.Template.Spec.Containers, 1)
+
+	// emulates the Job controller
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      buildJobName(build) + "-abcde",
+			Namespace: ns,
+			Labels:    map[string]string{jobNameLabel: buildJobName(build)},
+		},
+		Status: v1.PodStatus{
+			Phase:      v1.PodRunning,
+			Conditions: []v1.PodCondition{{Type: v1.PodScheduled, Status: v1.ConditionTrue}},
+		},
+	}
+	assert.NoError(t, c.Create(context.TODO(), pod))
+
+	build, err = FromBuild(build).WithClient(c).Reconcile(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, api.BuildPhaseRunning, build.Status.Phase)
+
+	job.Status.Conditions = []batchv1.JobCondition{{
+		Type:               batchv1.JobFailed,
+		Status:             v1.ConditionTrue,
+		Reason:             "DeadlineExceeded",
+		LastTransitionTime: metav1.Now(),
+	}}
+	assert.NoError(t, c.Update(context.TODO(), job))
+
+	build, err = FromBuild(build).WithClient(c).Reconcile(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, api.BuildPhaseFailed, build.Status.Phase)
+	assert.Equal(t, "Build timeout", build.Status.Error)
+}
+
+func TestWatchBuild(t *testing.T) {
+	defer func(period time.Duration) { watchResyncPeriod = period }(watchResyncPeriod)
+	watchResyncPeriod = 10 * time.Millisecond
+
+	ns := "test"
+	c, err := test.NewFakeClient()
+	assert.NoError(t, err)
+
+	dockerFile, err := os.ReadFile("testdata/Dockerfile")
+	assert.NoError(t, err)
+
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{
+			Namespace: ns,
+			Name:      "testPlatform",
+		},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy:   api.BuildStrategyPod,
+			PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+			Timeout:         &metav1.Duration{Duration: 5 * time.Minute},
+		},
+	}
+
+	scheduler, err := NewBuild(BuilderInfo{FinalImageName: "quay.io/kiegroup/buildexample:latest", BuildUniqueName: "build1", Platform: platform})
+	assert.NoError(t, err)
+	build, err := scheduler.
+		WithClient(c).
+		WithResource("Dockerfile", dockerFile).
+		Schedule(context.TODO())
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+
+	// The FakeClient won't run the pod, so we emulate it once it's created
+	updates, err := FromBuild(build).WithClient(c).Watch(ctx, func(build *api.Build, err error) {
+		assert.NoError(t, err)
+		pod := &v1.Pod{}
+		if err := c.Get(ctx, types.NamespacedName{Name: buildPodName(build), Namespace: ns}, pod); err == nil && pod.Status.Phase == "" {
+			pod.Status.Phase = v1.PodSucceeded
+			assert.NoError(t, c.Update(ctx, pod))
+		}
+	})
+	assert.NoError(t, err)
+
+	var phases []api.BuildPhase
+	for update := range updates {
+		build = update
+		phases = append(phases, update.Status.Phase)
+	}
+	assert.Equal(t, api.BuildPhaseSucceeded, build.Status.Phase)
+	assert.Contains(t, phases, api.BuildPhasePending)
+
+	build, err = FromBuild(build).WithClient(c).WaitForCompletion(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, api.BuildPhaseSucceeded, build.Status.Phase)
+}
+
+func TestWaitForCompletionTimeout(t *testing.T) {
+	defer func(period time.Duration) { watchResyncPeriod = period }(watchResyncPeriod)
+	watchResyncPeriod = 10 * time.Millisecond
+
+	c, err := test.NewFakeClient()
+	assert.NoError(t, err)
+
+	build := &api.Build{
+		ObjectReference: api.ObjectReference{Namespace: "test", Name: "build1"},
+		Spec:            api.BuildSpec{Strategy: api.BuildStrategyPod},
+		Status:          api.BuildStatus{Phase: api.BuildPhaseInterrupted},
+	}
+	build, err = FromBuild(build).WithClient(c).WaitForCompletion(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, api.BuildPhaseInterrupted, build.Status.Phase)
+
+	// the build keeps running since the FakeClient never runs the pod
+	build.Status.Phase = api.BuildPhaseRunning
+	build.Spec.Timeout = metav1.Duration{Duration: time.Minute}
+	now := metav1.Now()
+	build.Status.StartedAt = &now
+	assert.NoError(t, c.Create(context.TODO(), &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: buildPodName(build), Namespace: "test"}}))
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+	build, err = FromBuild(build).WithClient(c).WaitForCompletion(ctx)
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, api.BuildPhaseRunning, build.Status.Phase)
+}
+
+func TestWatchReconcileFailures(t *testing.T) {
+	defer func(period time.Duration) { watchResyncPeriod = period }(watchResyncPeriod)
+	watchResyncPeriod = 10 * time.Millisecond
+
+	c, err := test.NewFakeClient()
+	assert.NoError(t, err)
+	build := &api.Build{
+		ObjectReference: api.ObjectReference{Namespace: "test", Name: "build1"},
+		Spec:            api.BuildSpec{Strategy: api.BuildStrategyPod},
+		Status:          api.BuildStatus{Phase: api.BuildPhaseRunning},
+	}
+
+	// the watch stops once the reconciliation keeps failing, instead of retrying forever
+	var errs []error
+	updates, err := FromBuild(build).
+		WithClient(c).
+		WithAction(api.BuildPhaseRunning, ActionStageBefore, &testUnavailableAction{}).
+		Watch(context.TODO(), func(target *api.Build, err error) {
+			assert.Equal(t, api.BuildPhaseRunning, target.Status.Phase)
+			errs = append(errs, err)
+		})
+	assert.NoError(t, err)
+	for range updates {
+		assert.Fail(t, "no build expected")
+	}
+	assert.Len(t, errs, watchMaxFailures)
+	for _, err := range errs {
+		assert.ErrorContains(t, err, "notification service unavailable")
+	}
+
+	_, err = FromBuild(build).
+		WithClient(c).
+		WithAction(api.BuildPhaseRunning, ActionStageBefore, &testUnavailableAction{}).
+		WaitForCompletion(context.TODO())
+	assert.ErrorContains(t, err, "build build1 not finished: notification service unavailable")
+}
+
+func TestCancelBuild(t *testing.T) {
+	ns := "test"
+	build := &api.Build{
+		ObjectReference: api.ObjectReference{Namespace: ns, Name: "build1"},
+		Spec:            api.BuildSpec{Strategy: api.BuildStrategyPod},
+		Status:          api.BuildStatus{Phase: api.BuildPhaseRunning},
+	}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: buildPodName(build), Namespace: ns}}
+	c, err := test.NewFakeClient(pod)
+	assert.NoError(t, err)
+
+	recorder := record.NewFakeRecorder(10)
+	build, err = FromBuild(build).WithClient(c).WithEventRecorder(recorder, nil).CancelBuild(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, api.BuildPhaseInterrupted, build.Status.Phase)
+	assert.Equal(t, "Build cancelled", build.Status.Error)
+	assert.Equal(t, "Warning BuildPhaseChanged Build build1 moved from Running to Interrupted: Build cancelled", <-recorder.Events)
+
+	pod, err = getBuilderPod(context.TODO(), c, build)
+	assert.NoError(t, err)
+	assert.Nil(t, pod)
+}
+
+func TestBuildEvents(t *testing.T) {
+	ns := "test"
+	now := metav1.Now()
+	build := &api.Build{
+		ObjectReference: api.ObjectReference{Namespace: ns, Name: "build1"},
+		Spec: api.BuildSpec{
+			Strategy: api.BuildStrategyPod,
+			Timeout:  metav1.Duration{Duration: 5 * time.Minute},
+			Tasks: []api.Task{{Kaniko: &api.KanikoTask{
+				PublishTask: api.PublishTask{Image: "quay.io/kiegroup/buildexample:latest"},
+			}}},
+		},
+		Status: api.BuildStatus{Phase: api.BuildPhaseRunning, StartedAt: &now},
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: buildPodName(build), Namespace: ns},
+		Status:     v1.PodStatus{Phase: v1.PodSucceeded},
+	}
+	c, err := test.NewFakeClient(pod)
+	assert.NoError(t, err)
+
+	// the events are attached to the builder pod when no object is given
+	recorder := record.NewFakeRecorder(10)
+	build, err = FromBuild(build).WithClient(c).WithEventRecorder(recorder, nil).Reconcile(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, api.BuildPhaseSucceeded, build.Status.Phase)
+	assert.Equal(t, "Normal BuildPhaseChanged Build build1 moved from Running to Succeeded", <-recorder.Events)
+	assert.Equal(t, "Normal ImagePushed Image quay.io/kiegroup/buildexample:latest of build build1 pushed", <-recorder.Events)
+
+	// the build moves to the recovery
+	build.Status.Phase = api.BuildPhaseFailed
+	build.Status.Failure = &api.Failure{Time: metav1.NewTime(now.Add(-time.Hour)), Recovery: api.FailureRecovery{AttemptMax: 5}}
+	owner := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: ns}}
+	build, err = FromBuild(build).WithClient(c).WithEventRecorder(recorder, owner).Reconcile(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, api.BuildPhaseInitialization, build.Status.Phase)
+	assert.Equal(t, "Normal BuildPhaseChanged Build build1 moved from Failed to Initialization", <-recorder.Events)
+	assert.Equal(t, "Warning BuildRecovery Recovery attempt (1/5) of build build1", <-recorder.Events)
+	assert.Empty(t, recorder.Events)
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/kiegroup/container-builder/client"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ObjectDecorator is called with every object (Pod, ConfigMap, Secret) created by the builder right before sending it to the cluster.
+// Client code can use it to add their own labels, annotations or owner references.
+type ObjectDecorator func(obj ctrl.Object) error
+
+// OwnerReferenceDecorator sets the given owner as the controller of every object created by the builder,
+// so the Kubernetes garbage collector can clean them up once the owner is deleted.
+func OwnerReferenceDecorator(owner ctrl.Object, scheme *runtime.Scheme) ObjectDecorator {
+	return func(obj ctrl.Object) error {
+		return controllerutil.SetControllerReference(owner, obj, scheme)
+	}
+}
+
+// LabelsDecorator adds the given labels to every object created by the builder.
+func LabelsDecorator(labels map[string]string) ObjectDecorator {
+	return func(obj ctrl.Object) error {
+		objLabels := obj.GetLabels()
+		if objLabels == nil {
+			objLabels = make(map[string]string, len(labels))
+		}
+		for k, v := range labels {
+			objLabels[k] = v
+		}
+		obj.SetLabels(objLabels)
+		return nil
+	}
+}
+
+// decoratorClient wraps a client.Client to pass every object being created to the registered decorators.
+type decoratorClient struct {
+	client.Client
+	decorators []ObjectDecorator
+}
+
+var _ client.Client = &decoratorClient{}
+
+func newDecoratorClient(c client.Client, decorators []ObjectDecorator) client.Client {
+	if c == nil || len(decorators) == 0 {
+		return c
+	}
+	return &decoratorClient{Client: c, decorators: decorators}
+}
+
+func (c *decoratorClient) Create(ctx context.Context, obj ctrl.Object, opts ...ctrl.CreateOption) error {
+	for _, decorate := range c.decorators {
+		if err := decorate(obj); err != nil {
+			return errors.Wrapf(err, "cannot decorate object %s", obj.GetName())
+		}
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
@@ -26,7 +26,7 @@ func newErrorAction() Action {
 }
 
 type errorAction struct {
-	baseAction
+	BaseAction
 }
 
 // Name returns a common name of the action.
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/kiegroup/container-builder/api"
+)
+
+// Reasons of the events emitted by the builder
+const (
+	// ReasonBuildPhaseChanged the build moved to a new phase
+	ReasonBuildPhaseChanged = "BuildPhaseChanged"
+	// ReasonBuildRecovery the builder tries the build again after a failure
+	ReasonBuildRecovery = "BuildRecovery"
+	// ReasonBuildTimeout the build failed because it didn't finish in time
+	ReasonBuildTimeout = "BuildTimeout"
+	// ReasonImagePushed the built image has been pushed to the registry
+	ReasonImagePushed = "ImagePushed"
+	// ReasonImageReused the image of a previous build with the same inputs has been reused, see api.BuildSpec InputCache
+	ReasonImageReused = "ImageReused"
+)
+
+// buildTimeoutError the error set by the monitor actions when the build times out
+const buildTimeoutError = "Build timeout"
+
+// recordTransitionEvents emits the events describing the phase transition of the build, if an EventRecorder is set.
+// Events are attached to the involved object set along with the recorder or to the builder Pod, if any.
+func (b *builder) recordTransitionEvents(ctx context.Context, from api.BuildPhase, build *api.Build) {
+	if b.Context.Recorder == nil {
+		return
+	}
+	object := b.eventObject(ctx, build)
+	if object == nil {
+		b.L.Debug("No object to attach the build events to", "build", build.Name)
+		return
+	}
+
+	to := build.Status.Phase
+	eventType := corev1.EventTypeNormal
+	if to == api.BuildPhaseFailed || to == api.BuildPhaseError || to == api.BuildPhaseInterrupted {
+		eventType = corev1.EventTypeWarning
+	}
+	message := fmt.Sprintf("Build %s moved from %s to %s", build.Name, phaseName(from), to)
+	if len(build.Status.Error) > 0 && eventType == corev1.EventTypeWarning {
+		message = fmt.Sprintf("%s: %s", message, build.Status.Error)
+	}
+	b.Context.Recorder.Event(object, eventType, ReasonBuildPhaseChanged, message)
+
+	switch {
+	case to == api.BuildPhaseFailed && build.Status.Error == buildTimeoutError:
+		b.Context.Recorder.Eventf(object, corev1.EventTypeWarning, ReasonBuildTimeout,
+			"Build %s didn't finish within %s", build.Name, build.Spec.Timeout.Duration)
+	case from == api.BuildPhaseFailed && to == api.BuildPhaseInitialization && build.Status.Failure != nil:
+		b.Context.Recorder.Eventf(object, corev1.EventTypeWarning, ReasonBuildRecovery,
+			"Recovery attempt (%d/%d) of build %s", build.Status.Failure.Recovery.Attempt, build.Status.Failure.Recovery.AttemptMax, build.Name)
+	case from == api.BuildPhaseScheduling && to == api.BuildPhaseSucceeded:
+		b.Context.Recorder.Eventf(object, corev1.EventTypeNormal, ReasonImageReused,
+			"Image %s of build %s reused, built from the same inputs", build.Status.Image, build.Name)
+	case to == api.BuildPhaseSucceeded && len(build.Status.Image) > 0:
+		b.Context.Recorder.Eventf(object, corev1.EventTypeNormal, ReasonImagePushed,
+			"Image %s of build %s pushed", build.Status.Image, build.Name)
+	}
+}
+
+// eventObject returns the object the build events are attached to, nil if none
+func (b *builder) eventObject(ctx context.Context, build *api.Build) runtime.Object {
+	if b.Context.EventObject != nil {
+		return b.Context.EventObject
+	}
+	pod, err := getBuilderPod(ctx, b.Context.Client, build)
+	if err != nil || pod == nil {
+		return nil
+	}
+	return pod
+}
+
+func phaseName(phase api.BuildPhase) string {
+	if phase == api.BuildPhaseNone {
+		return "None"
+	}
+	return string(phase)
+}
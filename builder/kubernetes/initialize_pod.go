@@ -20,6 +20,7 @@ import (
 	"context"
 	"github.com/kiegroup/container-builder/api"
 	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func newInitializePodAction() Action {
@@ -27,7 +28,7 @@ func newInitializePodAction() Action {
 }
 
 type initializePodAction struct {
-	baseAction
+	BaseAction
 }
 
 // Name returns a common name of the action.
@@ -52,6 +53,18 @@ func (action *initializePodAction) Handle(ctx context.Context, build *api.Build)
 		return nil, err
 	}
 
+	if build.Spec.Job != nil {
+		job, err := getBuilderJob(ctx, action.client, build)
+		if err != nil || job != nil {
+			// Same for the job, that might be still deleting its pods
+			return nil, err
+		}
+	}
+
+	if timings := buildTimings(build); timings.CreatedAt == nil {
+		now := metav1.Now()
+		timings.CreatedAt = &now
+	}
 	build.Status.Phase = api.BuildPhaseScheduling
 
 	return build, nil
@@ -0,0 +1,270 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/client"
+	"github.com/kiegroup/container-builder/util/dockerfile"
+	"github.com/kiegroup/container-builder/util/registry"
+)
+
+const (
+	// inputHashLabel the label of the built images holding the hash of their inputs
+	inputHashLabel = "org.kie.kogito.build.inputs"
+	// inputHashTagPrefix the prefix of the tag pushed along with the built image, naming it by the hash of its inputs
+	inputHashTagPrefix = "inputs-"
+	// inputHashAlgorithm the algorithm of the input hash, prefixing it like the image digests
+	inputHashAlgorithm = "sha256:"
+)
+
+// buildInputs the inputs changing the image built by a Kaniko task, hashed to find the images built from the same inputs.
+// The task name, context directory and destination are left out, they don't change the image.
+type buildInputs struct {
+	// Resources the digests of the build resources, sorted by target
+	Resources []resourceInput `json:"resources"`
+	// BaseImages the digests of the images the build starts from
+	BaseImages      map[string]string `json:"baseImages,omitempty"`
+	Options         []string          `json:"options,omitempty"`
+	AdditionalFlags []string          `json:"additionalFlags,omitempty"`
+	BuildArgs       []buildArgInput   `json:"buildArgs,omitempty"`
+	// BuildSecrets the versions of the build secrets, by mount path
+	BuildSecrets map[string]string `json:"buildSecrets,omitempty"`
+}
+
+// buildArgInput a build argument, the values read from Secrets being identified by the Secret version to keep them out of the hash
+type buildArgInput struct {
+	Name          string `json:"name"`
+	Value         string `json:"value,omitempty"`
+	SecretVersion string `json:"secretVersion,omitempty"`
+}
+
+type resourceInput struct {
+	Target string `json:"target"`
+	Digest string `json:"digest"`
+}
+
+// setInputHash records the hash of the build inputs in the status when the input cache is enabled.
+// The build goes on without cache when the hash can't be computed, for example if a base image can't be resolved.
+func (s *scheduler) setInputHash(ctx context.Context) {
+	build := s.builder.Context.Build
+	task := kanikoTask(build)
+	if !build.Spec.InputCache || task == nil {
+		return
+	}
+	hash, err := s.inputHash(ctx, task)
+	if err != nil {
+		s.builder.L.Errorf(err, "Input cache disabled for build %s", build.Name)
+		return
+	}
+	build.Status.InputHash = hash
+}
+
+func (s *scheduler) inputHash(ctx context.Context, task *api.KanikoTask) (string, error) {
+	// the values of the secret build arguments are left out, like from the builder Pod
+	flags, _ := splitSecretBuildArgs(buildRedactor(s.builder.Context.Build), task.AdditionalFlags)
+	inputs := buildInputs{AdditionalFlags: flags}
+	addKanikoOptions(task, &inputs.Options)
+	var images []string
+	for _, r := range s.Resources {
+		sum := sha256.Sum256(r.Content)
+		inputs.Resources = append(inputs.Resources, resourceInput{Target: r.Target, Digest: inputHashAlgorithm + hex.EncodeToString(sum[:])})
+		if r.Target == dockerfileName {
+			baseImages, err := dockerfile.BaseImages(r.Content)
+			if err != nil {
+				return "", err
+			}
+			images = append(images, baseImages...)
+		}
+	}
+	sort.Slice(inputs.Resources, func(i, j int) bool {
+		return inputs.Resources[i].Target < inputs.Resources[j].Target
+	})
+	if err := s.addBuildArgInputs(ctx, task, &inputs); err != nil {
+		return "", err
+	}
+	if len(task.BaseImage) > 0 {
+		images = append(images, task.BaseImage)
+	}
+
+	if len(images) > 0 {
+		registryClient, err := newRegistryClient(ctx, s.builder.Context.Client, s.builder.Context.Build.Namespace, task.Registry)
+		if err != nil {
+			return "", err
+		}
+		inputs.BaseImages = map[string]string{}
+		for _, image := range images {
+			digest, err := registryClient.Digest(ctx, image)
+			if err != nil {
+				return "", err
+			}
+			if len(digest) == 0 {
+				return "", errors.Errorf("base image %s not found", image)
+			}
+			inputs.BaseImages[image] = digest.String()
+		}
+	}
+
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return inputHashAlgorithm + hex.EncodeToString(sum[:]), nil
+}
+
+// addBuildArgInputs adds the values of the build arguments and the versions of the Secrets to the inputs
+func (s *scheduler) addBuildArgInputs(ctx context.Context, task *api.KanikoTask, inputs *buildInputs) error {
+	c, namespace := s.builder.Context.Client, s.builder.Context.Build.Namespace
+	for _, arg := range task.BuildArgs {
+		input := buildArgInput{Name: arg.Name, Value: arg.Value}
+		if arg.ValueFrom != nil && arg.ValueFrom.ConfigMapKeyRef != nil {
+			ref := arg.ValueFrom.ConfigMapKeyRef
+			configMap := corev1.ConfigMap{}
+			if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, &configMap); err != nil && !k8serrors.IsNotFound(err) {
+				return errors.Wrapf(err, "cannot get the ConfigMap %s of build argument %s", ref.Name, arg.Name)
+			}
+			input.Value = configMap.Data[ref.Key]
+		}
+		if arg.ValueFrom != nil && arg.ValueFrom.SecretKeyRef != nil {
+			version, err := secretVersion(ctx, c, namespace, arg.ValueFrom.SecretKeyRef.Name)
+			if err != nil {
+				return err
+			}
+			input.SecretVersion = version
+		}
+		inputs.BuildArgs = append(inputs.BuildArgs, input)
+	}
+	for _, secret := range task.BuildSecrets {
+		version, err := secretVersion(ctx, c, namespace, secret.Name)
+		if err != nil {
+			return err
+		}
+		if inputs.BuildSecrets == nil {
+			inputs.BuildSecrets = map[string]string{}
+		}
+		inputs.BuildSecrets[buildSecretMountPath(secret)] = version
+	}
+	return nil
+}
+
+// secretVersion returns the resource version of the Secret, changing whenever its values change
+func secretVersion(ctx context.Context, c client.Client, namespace, name string) (string, error) {
+	secret := corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &secret); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "cannot get the Secret %s", name)
+	}
+	return name + "@" + secret.ResourceVersion, nil
+}
+
+// findInputHashImage returns the image built from the same inputs, nil if none or when the input cache is disabled.
+// The build goes on when the registry can't be checked.
+func (action *scheduleAction) findInputHashImage(ctx context.Context, build *api.Build) *registry.Image {
+	task := kanikoTask(build)
+	if !build.Spec.InputCache || len(build.Status.InputHash) == 0 || task == nil {
+		return nil
+	}
+	image, err := inputHashImage(task, build.Status.InputHash)
+	if err != nil {
+		action.L.Errorf(err, "Cannot check the input cache of build %s", build.Name)
+		return nil
+	}
+	registryClient, err := newRegistryClient(ctx, action.client, build.Namespace, task.Registry)
+	if err != nil {
+		action.L.Errorf(err, "Cannot check the input cache of build %s", build.Name)
+		return nil
+	}
+	found, err := registryClient.Image(ctx, image)
+	if err != nil {
+		action.L.Errorf(err, "Cannot check the input cache of build %s", build.Name)
+		return nil
+	}
+	// the tag might have been pushed by someone else
+	if found == nil || found.Labels[inputHashLabel] != build.Status.InputHash {
+		return nil
+	}
+	return found
+}
+
+// inputHashImage returns the image pushed along with the built one, tagged with the hash of its inputs
+func inputHashImage(task *api.KanikoTask, hash string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(publishedImage(task))
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid image %s", publishedImage(task))
+	}
+	tagged, err := reference.WithTag(reference.TrimNamed(named), inputHashTagPrefix+strings.TrimPrefix(hash, inputHashAlgorithm))
+	if err != nil {
+		return "", err
+	}
+	return tagged.String(), nil
+}
+
+// publishedImage returns the image pushed by the Kaniko task, joined with its registry address
+func publishedImage(task *api.KanikoTask) string {
+	if len(task.Registry.Address) == 0 {
+		return task.Image
+	}
+	return task.Registry.Address + "/" + task.Image
+}
+
+// newRegistryClient returns a client of the registries, authenticated with the Docker config of the registry secret, if any
+func newRegistryClient(ctx context.Context, c client.Client, namespace string, spec api.RegistrySpec) (*registry.Client, error) {
+	var config []byte
+	if len(spec.Secret) > 0 {
+		secret := corev1.Secret{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: spec.Secret}, &secret); err != nil {
+			return nil, errors.Wrapf(err, "cannot get the registry secret %s", spec.Secret)
+		}
+		for _, key := range []string{standardDockerKanikoRegistrySecret.fileName, plainDockerKanikoRegistrySecret.fileName} {
+			if data, ok := secret.Data[key]; ok {
+				config = data
+				break
+			}
+		}
+	}
+	var insecure []string
+	if spec.Insecure {
+		insecure = append(insecure, spec.Address)
+	}
+	return registry.NewClient(config, insecure...)
+}
+
+// kanikoTask returns the Kaniko task of the build, if any
+func kanikoTask(build *api.Build) *api.KanikoTask {
+	for _, task := range build.Spec.Tasks {
+		if task.Kaniko != nil {
+			return task.Kaniko
+		}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/util/test"
+)
+
+func TestInputCache(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+	pushTestImage(t, server.URL, "base", "1", nil)
+
+	c, err := test.NewFakeClient()
+	assert.NoError(t, err)
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{
+			Namespace: "test",
+			Name:      "testPlatform",
+		},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy:   api.BuildStrategyPod,
+			PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+			Registry:        api.RegistrySpec{Address: host, Insecure: true},
+			Timeout:         &metav1.Duration{Duration: 5 * time.Minute},
+			InputCache:      true,
+		},
+	}
+	dockerFile := []byte("FROM " + host + "/base:1 AS base\nFROM base\nRUN echo cached\n")
+	schedule := func(name string) *api.Build {
+		scheduler, err := NewBuild(BuilderInfo{FinalImageName: "app:latest", BuildUniqueName: name, Platform: platform})
+		assert.NoError(t, err)
+		build, err := scheduler.WithClient(c).WithResource("Dockerfile", dockerFile).Schedule(context.TODO())
+		assert.NoError(t, err)
+		build, err = FromBuild(build).WithClient(c).Reconcile(context.TODO())
+		assert.NoError(t, err)
+		return build
+	}
+
+	build := schedule("build1")
+	assert.True(t, strings.HasPrefix(build.Status.InputHash, "sha256:"))
+	assert.Equal(t, api.BuildPhasePending, build.Status.Phase)
+
+	// the built image is labelled and tagged with the input hash
+	build, err = FromBuild(build).WithClient(c).Reconcile(context.TODO())
+	assert.NoError(t, err)
+	pod, err := getBuilderPod(context.TODO(), c, build)
+	assert.NoError(t, err)
+	tag := "inputs-" + strings.TrimPrefix(build.Status.InputHash, "sha256:")
+	assert.Contains(t, pod.Spec.Containers[0].Args, "--label=org.kie.kogito.build.inputs="+build.Status.InputHash)
+	assert.Contains(t, pod.Spec.Containers[0].Args, "--destination="+host+"/app:"+tag)
+
+	// once pushed, the image is reused by the builds with the same inputs
+	pushed := pushTestImage(t, server.URL, "app", tag, map[string]string{inputHashLabel: build.Status.InputHash})
+	reused := schedule("build2")
+	assert.Equal(t, build.Status.InputHash, reused.Status.InputHash)
+	assert.Equal(t, api.BuildPhaseSucceeded, reused.Status.Phase)
+	assert.Equal(t, pushed.String(), reused.Status.Digest)
+	assert.Equal(t, "app:latest", reused.Status.Image)
+
+	// a new base image changes the inputs
+	pushTestImage(t, server.URL, "base", "1", map[string]string{"version": "2"})
+	rebuilt := schedule("build3")
+	assert.NotEqual(t, build.Status.InputHash, rebuilt.Status.InputHash)
+	assert.Equal(t, api.BuildPhasePending, rebuilt.Status.Phase)
+
+	// the builds go on without cache when the base images can't be resolved
+	dockerFile = []byte("ARG VERSION\nFROM " + host + "/base:${VERSION}\n")
+	uncached := schedule("build4")
+	assert.Empty(t, uncached.Status.InputHash)
+	assert.Equal(t, api.BuildPhasePending, uncached.Status.Phase)
+}
+
+func TestInputCacheDetectedRegistry(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	// the registry of the cluster, as told by KEP-1755
+	c, err := test.NewFakeClient(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-public", Name: "local-registry-hosting"},
+		Data:       map[string]string{"localRegistryHosting.v1": "hostFromClusterNetwork: " + host + "\n"},
+	})
+	assert.NoError(t, err)
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{Namespace: "test", Name: "testPlatform"},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy:   api.BuildStrategyPod,
+			PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+			Registry:        api.RegistrySpec{Insecure: true},
+			InputCache:      true,
+		},
+	}
+	schedule := func(name string) *api.Build {
+		scheduler, err := NewBuild(BuilderInfo{FinalImageName: "app:latest", BuildUniqueName: name, Platform: platform})
+		assert.NoError(t, err)
+		build, err := scheduler.WithClient(c).WithResource("Dockerfile", []byte("FROM scratch\nLABEL cached=true\n")).Schedule(context.TODO())
+		assert.NoError(t, err)
+		build, err = FromBuild(build).WithClient(c).Reconcile(context.TODO())
+		assert.NoError(t, err)
+		return build
+	}
+
+	// the detected registry is kept in the build, so that the input hash image is looked up where it's pushed
+	build := schedule("build1")
+	assert.Equal(t, host, kanikoTask(build).Registry.Address)
+	tag := "inputs-" + strings.TrimPrefix(build.Status.InputHash, "sha256:")
+	pushed := pushTestImage(t, server.URL, "app", tag, map[string]string{inputHashLabel: build.Status.InputHash})
+	reused := schedule("build2")
+	assert.Equal(t, api.BuildPhaseSucceeded, reused.Status.Phase)
+	assert.Equal(t, pushed.String(), reused.Status.Digest)
+}
+
+// pushTestImage pushes an image without layers to the registry, returning its digest
+func pushTestImage(t *testing.T, url, repository, tag string, labels map[string]string) digest.Digest {
+	config, err := json.Marshal(ocispec.Image{
+		Architecture: "amd64",
+		OS:           "linux",
+		Config:       ocispec.ImageConfig{Labels: labels},
+		RootFS:       ocispec.RootFS{Type: "layers"},
+	})
+	assert.NoError(t, err)
+	configDigest := digest.FromBytes(config)
+	putTestContent(t, http.MethodPost, url+"/v2/"+repository+"/blobs/uploads/?digest="+configDigest.String(), "application/octet-stream", config)
+
+	manifest, err := json.Marshal(schema2.Manifest{
+		Versioned: schema2.SchemaVersion,
+		Config: distribution.Descriptor{
+			MediaType: schema2.MediaTypeImageConfig,
+			Digest:    configDigest,
+			Size:      int64(len(config)),
+		},
+	})
+	assert.NoError(t, err)
+	putTestContent(t, http.MethodPut, url+"/v2/"+repository+"/manifests/"+tag, schema2.MediaTypeManifest, manifest)
+	return digest.FromBytes(manifest)
+}
+
+func putTestContent(t *testing.T, method, url, mediaType string, content []byte) {
+	request, err := http.NewRequest(method, url, bytes.NewReader(content))
+	assert.NoError(t, err)
+	request.Header.Set("Content-Type", mediaType)
+	response, err := http.DefaultClient.Do(request)
+	assert.NoError(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+}
@@ -22,9 +22,10 @@ import (
 
 	"github.com/kiegroup/container-builder/api"
 	"github.com/kiegroup/container-builder/client"
-	"github.com/kiegroup/container-builder/util/defaults"
 	"github.com/kiegroup/container-builder/util/minikube"
+	"github.com/kiegroup/container-builder/util/mirrors"
 	"github.com/kiegroup/container-builder/util/registry"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -53,38 +54,71 @@ var (
 	}
 )
 
-func addKanikoTaskToPod(ctx context.Context, c client.Client, build *api.Build, task *api.KanikoTask, pod *corev1.Pod) error {
+// findRegistryAddress returns the address of the registry of the cluster, like the minikube one, empty if none is found
+func findRegistryAddress(ctx context.Context, c client.Client) (string, error) {
 	// TODO: perform an actual registry lookup based on the environment
+	address, err := registry.GetRegistryAddress(ctx, c)
+	if err != nil {
+		return "", err
+	}
+	if address == nil {
+		if address, err = minikube.FindRegistry(ctx, c); err != nil {
+			return "", err
+		}
+	}
+	if address == nil {
+		return "", nil
+	}
+	return *address, nil
+}
+
+// resolveRegistryAddress sets the registry address of the Kaniko task to the one of the cluster when it's not set,
+// so that the image is pushed to and looked up in the same registry, like its input hash image
+func (s *scheduler) resolveRegistryAddress(ctx context.Context) error {
+	task := kanikoTask(s.builder.Context.Build)
+	if task == nil || len(task.Registry.Address) > 0 || s.builder.Context.Client == nil {
+		return nil
+	}
+	address, err := findRegistryAddress(ctx, s.builder.Context.Client)
+	if err != nil {
+		return errors.Wrapf(err, "cannot find the registry of build %s", s.builder.Context.Build.Name)
+	}
+	task.Registry.Address = address
+	return nil
+}
+
+func addKanikoTaskToPod(ctx context.Context, c client.Client, build *api.Build, task *api.KanikoTask, pod *corev1.Pod) error {
 	if task.Registry.Address == "" {
-		address, err := registry.GetRegistryAddress(ctx, c)
+		address, err := findRegistryAddress(ctx, c)
 		if err != nil {
 			return err
 		}
-		if address != nil {
-			task.Registry.Address = *address
-		} else {
-			address, err := minikube.FindRegistry(ctx, c)
-			if err != nil {
-				return err
-			}
-			if address != nil {
-				task.Registry.Address = *address
-			}
-		}
+		task.Registry.Address = address
 	}
 
 	// TODO: verify how cache is possible
 	// TODO: the PlatformBuild structure should be able to identify the Kaniko context. For simplicity, let's use a CM with `dir://`
 	args := []string{
-		"--dockerfile=Dockerfile",
+		"--dockerfile=" + dockerfileName,
 		"--context=dir://" + task.ContextDir,
 		"--destination=" + task.Registry.Address + "/" + task.Image,
 	}
 
-	if task.AdditionalFlags != nil && len(task.AdditionalFlags) > 0 {
-		args = append(args, task.AdditionalFlags...)
+	if hash := build.Status.InputHash; len(hash) > 0 {
+		image, err := inputHashImage(task, hash)
+		if err != nil {
+			return err
+		}
+		args = append(args, "--label="+inputHashLabel+"="+hash, "--destination="+image)
 	}
 
+	addKanikoOptions(task, &args)
+
+	// the values of the secret build arguments are kept out of the Pod
+	redactor := buildRedactor(build)
+	flags, secretArgs := splitSecretBuildArgs(redactor, task.AdditionalFlags)
+	args = append(args, flags...)
+
 	if task.Verbose != nil && *task.Verbose {
 		args = append(args, "-v=debug")
 	}
@@ -106,6 +140,15 @@ func addKanikoTaskToPod(ctx context.Context, c client.Client, build *api.Build,
 		args = append(args, "--insecure")
 		args = append(args, "--insecure-pull")
 	}
+	args = append(args, mirrors.KanikoFlags(task.Registry.Mirrors)...)
+
+	addBuildArgs(redactor, task, &args, &env, secretArgs)
+	if len(secretArgs) > 0 {
+		if err := addSecretBuildArgs(ctx, c, build, secretArgs, &env); err != nil {
+			return err
+		}
+	}
+	addBuildSecrets(task, &volumes, &volumeMounts)
 
 	// TODO: should be handled by a mount build context handler instead since we can have many possibilities
 	if err := addResourcesToVolume(ctx, c, task.PublishTask, build, &volumes, &volumeMounts); err != nil {
@@ -114,20 +157,26 @@ func addKanikoTaskToPod(ctx context.Context, c client.Client, build *api.Build,
 
 	env = append(env, proxyFromEnvironment()...)
 
+	podSecurityContext, securityContext, err := kanikoSecurityContexts(ctx, c, pod.Namespace, build.Spec.GetSecurityProfile())
+	if err != nil {
+		return err
+	}
+
 	container := corev1.Container{
 		Name:            strings.ToLower(task.Name),
-		Image:           defaults.KanikoExecutorImage,
+		Image:           task.GetExecutorImage(),
 		ImagePullPolicy: corev1.PullIfNotPresent,
 		Args:            args,
 		Env:             env,
 		WorkingDir:      task.ContextDir,
 		VolumeMounts:    volumeMounts,
 		Resources:       task.Resources,
-		//SecurityContext: KanikoSecurityDefaults(),
+		SecurityContext: securityContext,
 	}
 
 	// We may want to handle possible conflicts
 	pod.Spec.Affinity = affinity
+	pod.Spec.SecurityContext = podSecurityContext
 	pod.Spec.Volumes = append(pod.Spec.Volumes, volumes...)
 	pod.Spec.Containers = append(pod.Spec.Containers, container)
 
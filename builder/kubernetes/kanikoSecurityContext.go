@@ -16,10 +16,23 @@
 package kubernetes
 
 import (
+	"context"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/client"
 	"github.com/kiegroup/container-builder/util"
+	"github.com/kiegroup/container-builder/util/openshift"
 	corev1 "k8s.io/api/core/v1"
 )
 
+// kanikoNonRootUser is the user ID used to run Kaniko with the restricted profile outside OpenShift
+const kanikoNonRootUser int64 = 1000
+
+// kanikoIncompatibleProfiles lists the security profiles Kaniko can't reliably run with, and why
+var kanikoIncompatibleProfiles = map[api.SecurityProfile]string{
+	api.SecurityProfileRestricted: "Kaniko must run as root to extract the base image file system, builds might fail with the restricted profile",
+}
+
 func KanikoSecurityDefaults() *corev1.SecurityContext {
 	return &corev1.SecurityContext{
 		AllowPrivilegeEscalation: util.Pbool(false),
@@ -33,3 +46,51 @@ func KanikoSecurityDefaults() *corev1.SecurityContext {
 		},
 	}
 }
+
+// kanikoSecurityContexts returns the Pod and container security contexts matching the given profile.
+// On OpenShift, the restricted profile runs with the UID assigned to the namespace to be compatible with the restricted SCCs.
+func kanikoSecurityContexts(ctx context.Context, c client.Client, namespace string, profile api.SecurityProfile) (*corev1.PodSecurityContext, *corev1.SecurityContext, error) {
+	seccomp := &corev1.SeccompProfile{
+		Type: corev1.SeccompProfileTypeRuntimeDefault,
+	}
+
+	switch profile {
+	case api.SecurityProfilePrivileged:
+		return &corev1.PodSecurityContext{}, &corev1.SecurityContext{
+			Privileged:               util.Pbool(true),
+			AllowPrivilegeEscalation: util.Pbool(true),
+		}, nil
+
+	case api.SecurityProfileRestricted:
+		uid, err := kanikoNonRootUID(ctx, c, namespace)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &corev1.PodSecurityContext{
+			RunAsNonRoot:   util.Pbool(true),
+			RunAsUser:      uid,
+			SeccompProfile: seccomp,
+		}, KanikoSecurityDefaults(), nil
+
+	default:
+		return &corev1.PodSecurityContext{
+			SeccompProfile: seccomp,
+		}, &corev1.SecurityContext{
+			Privileged: util.Pbool(false),
+		}, nil
+	}
+}
+
+// kanikoNonRootUID returns the UID to run Kaniko as non-root user.
+// A nil UID on OpenShift means that the SCC admission will assign one.
+func kanikoNonRootUID(ctx context.Context, c client.Client, namespace string) (*int64, error) {
+	isOpenShift, err := c.IsOpenShift()
+	if err != nil {
+		return nil, err
+	}
+	if isOpenShift {
+		return openshift.GetNamespaceUID(ctx, c, namespace)
+	}
+	uid := kanikoNonRootUser
+	return &uid, nil
+}
@@ -0,0 +1,298 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/hashicorp/go-version"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/util/defaults"
+	"github.com/kiegroup/container-builder/util/mirrors"
+	"github.com/pkg/errors"
+)
+
+// kanikoFeature the versions of the Kaniko executor supporting a feature, both bounds being optional and included
+type kanikoFeature struct {
+	since string
+	until string
+}
+
+var (
+	// kanikoKillFeature the kill command of the executor image, used to signal the builds timing out
+	kanikoKillFeature = kanikoFeature{until: defaults.KanikoVersionSupportingKill}
+	// kanikoRedoSnapshotFeature the redo snapshot mode
+	kanikoRedoSnapshotFeature = kanikoFeature{since: "1.3.0"}
+	// kanikoFlags the flags of the Kaniko executor, by name, with the releases introducing or removing them
+	kanikoFlags = map[string]kanikoFeature{
+		"--build-arg":                       {},
+		"--cache":                           {},
+		"--cache-copy-layers":               {since: "1.3.0"},
+		"--cache-dir":                       {},
+		"--cache-repo":                      {},
+		"--cache-run-layers":                {since: "1.9.0"},
+		"--cache-ttl":                       {since: "0.9.0"},
+		"--cleanup":                         {since: "0.11.0"},
+		"--compressed-caching":              {since: "1.5.0"},
+		"--context":                         {},
+		"-c":                                {},
+		"--context-sub-path":                {since: "0.17.0"},
+		"--custom-platform":                 {since: "1.5.0"},
+		"--destination":                     {},
+		"-d":                                {},
+		"--digest-file":                     {since: "0.10.0"},
+		"--dockerfile":                      {},
+		"-f":                                {},
+		"--force":                           {},
+		"--force-build-metadata":            {since: "1.9.0"},
+		"--git":                             {since: "1.7.0"},
+		"--ignore-path":                     {since: "1.0.0"},
+		"--ignore-var-run":                  {since: "1.0.0"},
+		"--image-download-retry":            {since: "1.9.0"},
+		"--image-fs-extract-retry":          {since: "1.7.0"},
+		"--image-name-tag-with-digest-file": {since: "1.6.0"},
+		"--image-name-with-digest-file":     {since: "0.17.0"},
+		"--insecure":                        {},
+		"--insecure-pull":                   {since: "0.9.0"},
+		"--insecure-registry":               {since: "0.17.0"},
+		"--kaniko-dir":                      {since: "1.7.0"},
+		"--label":                           {since: "0.14.0"},
+		"--log-format":                      {since: "0.16.0"},
+		"--log-timestamp":                   {since: "0.24.0"},
+		"--no-push":                         {},
+		"--oci-layout-path":                 {since: "0.10.0"},
+		"--push-retry":                      {since: "1.4.0"},
+		"--registry-certificate":            {since: "0.17.0"},
+		"--registry-map":                    {since: "1.10.0"},
+		"--registry-mirror":                 {since: "0.10.0"},
+		"--reproducible":                    {since: "0.10.0"},
+		"--single-snapshot":                 {since: "0.9.0"},
+		"--skip-tls-verify":                 {},
+		"--skip-tls-verify-pull":            {since: "0.9.0"},
+		"--skip-tls-verify-registry":        {since: "0.17.0"},
+		"--skip-unused-stages":              {since: "1.1.0"},
+		"--snapshotMode":                    {since: "0.9.0"},
+		"--tar-path":                        {},
+		"--target":                          {since: "0.9.0"},
+		"--use-new-run":                     {since: "1.3.0"},
+		"--verbosity":                       {},
+		"-v":                                {},
+		"--whitelist-var-run":               {since: "0.17.0", until: "0.24.0"},
+	}
+)
+
+// supportedBy returns true if the given version of the Kaniko executor supports the feature
+func (f kanikoFeature) supportedBy(kaniko *version.Version) bool {
+	if len(f.since) > 0 && kaniko.LessThan(version.Must(version.NewVersion(f.since))) {
+		return false
+	}
+	if len(f.until) > 0 && kaniko.GreaterThan(version.Must(version.NewVersion(f.until))) {
+		return false
+	}
+	return true
+}
+
+func (f kanikoFeature) String() string {
+	switch {
+	case len(f.since) > 0 && len(f.until) > 0:
+		return "from Kaniko " + f.since + " to " + f.until
+	case len(f.until) > 0:
+		return "up to Kaniko " + f.until
+	default:
+		return "from Kaniko " + f.since
+	}
+}
+
+var (
+	// kanikoVersionTag the tags of the executor images telling their Kaniko version, like "v1.9.0" or "v1.9.0-debug"
+	kanikoVersionTag = regexp.MustCompile(`^v?([0-9]+\.[0-9]+\.[0-9]+)`)
+	// kanikoVersionLabels the labels of the executor images which may tell their Kaniko version, when their tag doesn't
+	kanikoVersionLabels = []string{"org.opencontainers.image.version", "version"}
+)
+
+// kanikoVersion returns the version of the Kaniko executor running the build: the version found while scheduling it,
+// else the version of the executor image tag, else the version of the default executor image
+func kanikoVersion(build *api.Build) *version.Version {
+	if v, err := version.NewVersion(build.Status.ExecutorVersion); err == nil {
+		return v
+	}
+	if task := kanikoTask(build); task != nil {
+		if v := kanikoTagVersion(task.GetExecutorImage()); v != nil {
+			return v
+		}
+	}
+	return version.Must(version.NewVersion(defaults.KanikoVersion))
+}
+
+// kanikoTagVersion returns the Kaniko version of the given image tag, nil if the tag doesn't tell it
+func kanikoTagVersion(image string) *version.Version {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return nil
+	}
+	tagged, ok := named.(reference.Tagged)
+	if !ok {
+		return nil
+	}
+	match := kanikoVersionTag.FindStringSubmatch(tagged.Tag())
+	if match == nil {
+		return nil
+	}
+	return version.Must(version.NewVersion(match[1]))
+}
+
+// resolveKanikoVersion records the Kaniko version of the executor image in the build status. The version is read from the image labels
+// when its tag doesn't tell it, like with the "latest" tag of a mirror, else the version of the default executor image is assumed.
+func (s *scheduler) resolveKanikoVersion(ctx context.Context) {
+	build := s.builder.Context.Build
+	task := kanikoTask(build)
+	if task == nil || len(build.Status.ExecutorVersion) > 0 {
+		return
+	}
+	image := task.GetExecutorImage()
+	if v := kanikoTagVersion(image); v != nil {
+		build.Status.ExecutorVersion = v.String()
+		return
+	}
+	v, err := s.kanikoLabelVersion(ctx, task, image)
+	if v == nil {
+		s.builder.L.Warn("cannot find the Kaniko version of the executor image, assuming the default one",
+			"image", image, "version", defaults.KanikoVersion, "reason", err)
+		return
+	}
+	build.Status.ExecutorVersion = v.String()
+}
+
+// kanikoLabelVersion returns the Kaniko version read from the labels of the given executor image, nil if they don't tell it
+func (s *scheduler) kanikoLabelVersion(ctx context.Context, task *api.KanikoTask, image string) (*version.Version, error) {
+	if s.builder.Context.Client == nil {
+		return nil, errors.New("no client to read the image labels")
+	}
+	// the executor image is usually mirrored in the registry of the built images
+	registryClient, err := newRegistryClient(ctx, s.builder.Context.Client, s.builder.Context.Build.Namespace, task.Registry)
+	if err != nil {
+		return nil, err
+	}
+	config, err := registryClient.Image(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, errors.Errorf("image %s not found", image)
+	}
+	for _, label := range kanikoVersionLabels {
+		if match := kanikoVersionTag.FindStringSubmatch(config.Labels[label]); match != nil {
+			return version.Must(version.NewVersion(match[1])), nil
+		}
+	}
+	return nil, errors.Errorf("no version label in %v", kanikoVersionLabels)
+}
+
+// kanikoOption an option of a Kaniko task, passed to the executor with a flag
+type kanikoOption struct {
+	// field the name of the option in the task
+	field string
+	flag  string
+	value string
+}
+
+func (o kanikoOption) String() string {
+	return o.flag + "=" + o.value
+}
+
+// kanikoOptions returns the options set by the task, in the order of the KanikoOptions fields
+func kanikoOptions(options *api.KanikoOptions) []kanikoOption {
+	var result []kanikoOption
+	addBool := func(field, flag string, value *bool) {
+		if value != nil {
+			result = append(result, kanikoOption{field: field, flag: flag, value: strconv.FormatBool(*value)})
+		}
+	}
+	if len(options.SnapshotMode) > 0 {
+		result = append(result, kanikoOption{field: "snapshotMode", flag: "--snapshotMode", value: string(options.SnapshotMode)})
+	}
+	addBool("useNewRun", "--use-new-run", options.UseNewRun)
+	addBool("compressedCaching", "--compressed-caching", options.CompressedCaching)
+	addBool("reproducible", "--reproducible", options.Reproducible)
+	if options.PushRetry != nil {
+		result = append(result, kanikoOption{field: "pushRetry", flag: "--push-retry", value: strconv.Itoa(int(*options.PushRetry))})
+	}
+	if len(options.Target) > 0 {
+		result = append(result, kanikoOption{field: "target", flag: "--target", value: options.Target})
+	}
+	addBool("singleSnapshot", "--single-snapshot", options.SingleSnapshot)
+	addBool("cleanup", "--cleanup", options.Cleanup)
+	return result
+}
+
+// addKanikoOptions passes the options of the task to Kaniko
+func addKanikoOptions(task *api.KanikoTask, args *[]string) {
+	for _, option := range kanikoOptions(&task.Options) {
+		*args = append(*args, option.String())
+	}
+}
+
+// validateKanikoOptions returns the errors found in the options and the registry mirrors of the Kaniko tasks which the given Kaniko
+// version doesn't support, and the warnings about the additional flags it doesn't know, which are passed to the executor as they are
+func validateKanikoOptions(build *api.Build, kaniko *version.Version) (field.ErrorList, []string) {
+	var errs field.ErrorList
+	var warnings []string
+	for i, task := range build.Spec.Tasks {
+		if task.Kaniko == nil {
+			continue
+		}
+		taskPath := field.NewPath("spec", "tasks").Index(i).Child("kaniko")
+		options := map[string]kanikoOption{}
+		for _, option := range kanikoOptions(&task.Kaniko.Options) {
+			options[option.flag] = option
+			optionPath := taskPath.Child("options", option.field)
+			if feature := kanikoFlags[option.flag]; !feature.supportedBy(kaniko) {
+				errs = append(errs, field.Invalid(optionPath, option.value, fmt.Sprintf("not supported by Kaniko %s, supported %s", kaniko, feature)))
+			} else if option.field == "snapshotMode" && option.value == string(api.KanikoSnapshotModeRedo) && !kanikoRedoSnapshotFeature.supportedBy(kaniko) {
+				errs = append(errs, field.Invalid(optionPath, option.value, fmt.Sprintf("not supported by Kaniko %s, supported %s", kaniko, kanikoRedoSnapshotFeature)))
+			}
+		}
+		for j, mirror := range task.Kaniko.Registry.Mirrors {
+			if feature := kanikoFlags["--registry-map"]; !mirrors.IsDockerHub(mirror.Registry) && !feature.supportedBy(kaniko) {
+				errs = append(errs, field.Invalid(taskPath.Child("registry", "mirrors").Index(j).Child("registry"), mirror.Registry,
+					fmt.Sprintf("only the Docker Hub mirrors are supported by Kaniko %s, the mirrors of the other registries are supported %s", kaniko, feature)))
+			}
+		}
+		for j, flag := range task.Kaniko.AdditionalFlags {
+			if !strings.HasPrefix(flag, "-") {
+				// the value of the previous flag
+				continue
+			}
+			name := strings.SplitN(flag, "=", 2)[0]
+			if option, ok := options[name]; ok {
+				errs = append(errs, field.Invalid(taskPath.Child("additionalFlags").Index(j), flag, "the "+name+" flag is set by options."+option.field))
+			} else if feature, ok := kanikoFlags[name]; !ok {
+				warnings = append(warnings, fmt.Sprintf("%s: unknown flag %s of Kaniko %s", taskPath.Child("additionalFlags").Index(j), name, kaniko))
+			} else if !feature.supportedBy(kaniko) {
+				warnings = append(warnings, fmt.Sprintf("%s: flag %s not supported by Kaniko %s, supported %s", taskPath.Child("additionalFlags").Index(j), name, kaniko, feature))
+			}
+		}
+	}
+	return errs, warnings
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/util/defaults"
+	"github.com/kiegroup/container-builder/util/test"
+)
+
+func TestKanikoOptions(t *testing.T) {
+	ns := "test"
+	c, err := test.NewFakeClient()
+	assert.NoError(t, err)
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{Namespace: ns, Name: "testPlatform"},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy:   api.BuildStrategyPod,
+			PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+		},
+	}
+	yes, retries := true, int32(3)
+	options := api.KanikoOptions{
+		SnapshotMode:   api.KanikoSnapshotModeRedo,
+		UseNewRun:      &yes,
+		PushRetry:      &retries,
+		Target:         "runtime",
+		SingleSnapshot: &yes,
+	}
+
+	// the options can't be set twice
+	scheduler, err := NewBuild(BuilderInfo{FinalImageName: "quay.io/kiegroup/buildexample:latest", BuildUniqueName: "options", Platform: platform})
+	assert.NoError(t, err)
+	_, err = scheduler.WithClient(c).
+		WithResource("Dockerfile", []byte("FROM busybox AS runtime\n")).
+		WithProperty(KanikoOptions, options).
+		WithAdditionalArgs([]string{"--use-new-run=false"}).
+		Schedule(context.TODO())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.tasks[0].kaniko.additionalFlags[0]: Invalid value: \"--use-new-run=false\": the --use-new-run flag is set by options.useNewRun")
+
+	scheduler, err = NewBuild(BuilderInfo{FinalImageName: "quay.io/kiegroup/buildexample:latest", BuildUniqueName: "options", Platform: platform})
+	assert.NoError(t, err)
+	build, err := scheduler.WithClient(c).
+		WithResource("Dockerfile", []byte("FROM busybox AS runtime\n")).
+		WithProperty(KanikoOptions, options).
+		WithAdditionalArgs([]string{"--cache-ttl=1h"}).
+		Schedule(context.TODO())
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		build, err = FromBuild(build).WithClient(c).Reconcile(context.TODO())
+		assert.NoError(t, err)
+	}
+
+	pod, err := getBuilderPod(context.TODO(), c, build)
+	assert.NoError(t, err)
+	args := pod.Spec.Containers[0].Args
+	assert.Contains(t, args, "--snapshotMode=redo")
+	assert.Contains(t, args, "--use-new-run=true")
+	assert.Contains(t, args, "--push-retry=3")
+	assert.Contains(t, args, "--target=runtime")
+	assert.Contains(t, args, "--single-snapshot=true")
+	assert.Contains(t, args, "--cache-ttl=1h")
+	assert.NotContains(t, args, "--cleanup=false")
+}
+
+func TestValidateKanikoOptions(t *testing.T) {
+	yes, retries := true, int32(-1)
+	build := &api.Build{Spec: api.BuildSpec{Tasks: []api.Task{{Kaniko: &api.KanikoTask{
+		PublishTask:     api.PublishTask{Image: "greetings:latest"},
+		Options:         api.KanikoOptions{SnapshotMode: api.KanikoSnapshotModeRedo, CompressedCaching: &yes, Cleanup: &yes, PushRetry: &retries},
+		AdditionalFlags: []string{"--use-new-run", "--whitelist-var-run=false", "--unknown", "--build-arg", "VERSION=1.0"},
+	}}}}}
+
+	errs := build.Validate().ToAggregate().Error()
+	assert.Contains(t, errs, "spec.tasks[0].kaniko.options.pushRetry: Invalid value: -1: must be greater than or equal to 0")
+
+	errs = validateKanikoOptionsError(t, build, "1.2.0")
+	assert.Contains(t, errs, "spec.tasks[0].kaniko.options.snapshotMode: Invalid value: \"redo\": not supported by Kaniko 1.2.0, supported from Kaniko 1.3.0")
+	assert.Contains(t, errs, "spec.tasks[0].kaniko.options.compressedCaching: Invalid value: \"true\": not supported by Kaniko 1.2.0, supported from Kaniko 1.5.0")
+	assert.NotContains(t, errs, "cleanup")
+
+	kaniko := version.Must(version.NewVersion("1.2.0"))
+	_, warnings := validateKanikoOptions(build, kaniko)
+	assert.Equal(t, []string{
+		"spec.tasks[0].kaniko.additionalFlags[0]: flag --use-new-run not supported by Kaniko 1.2.0, supported from Kaniko 1.3.0",
+		"spec.tasks[0].kaniko.additionalFlags[1]: flag --whitelist-var-run not supported by Kaniko 1.2.0, supported from Kaniko 0.17.0 to 0.24.0",
+		"spec.tasks[0].kaniko.additionalFlags[2]: unknown flag --unknown of Kaniko 1.2.0",
+	}, warnings)
+
+	assert.Empty(t, validateKanikoOptionsError(t, build, "1.9.0"))
+	assert.True(t, kanikoKillFeature.supportedBy(version.Must(version.NewVersion("0.17.1"))))
+	assert.False(t, kanikoKillFeature.supportedBy(kanikoVersion(build)))
+}
+
+func TestKanikoExecutorImage(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+	pushTestImage(t, server.URL, "kaniko/executor", "latest", map[string]string{"org.opencontainers.image.version": "v1.8.1"})
+
+	ns := "test"
+	c, err := test.NewFakeClient()
+	assert.NoError(t, err)
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{Namespace: ns, Name: "testPlatform"},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy:   api.BuildStrategyPod,
+			PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+			Registry:        api.RegistrySpec{Address: host, Insecure: true},
+		},
+	}
+	assert.Equal(t, defaults.KanikoExecutorImage, platform.Spec.GetExecutorImage())
+	t.Setenv(defaults.KanikoExecutorImageEnv, host+"/kaniko/executor:v1.2.0-debug")
+	assert.Equal(t, host+"/kaniko/executor:v1.2.0-debug", platform.Spec.GetExecutorImage())
+
+	// the version of the image tag is used to validate the options
+	yes := true
+	scheduler, err := NewBuild(BuilderInfo{FinalImageName: "buildexample:latest", BuildUniqueName: "executor", Platform: platform})
+	assert.NoError(t, err)
+	_, err = scheduler.WithClient(c).
+		WithResource("Dockerfile", []byte("FROM busybox\n")).
+		WithProperty(KanikoOptions, api.KanikoOptions{UseNewRun: &yes}).
+		Schedule(context.TODO())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.tasks[0].kaniko.options.useNewRun: Invalid value: \"true\": not supported by Kaniko 1.2.0")
+
+	// the version of the image labels is used when the tag doesn't tell it
+	platform.Spec.ExecutorImage = host + "/kaniko/executor:latest"
+	scheduler, err = NewBuild(BuilderInfo{FinalImageName: "buildexample:latest", BuildUniqueName: "executor", Platform: platform})
+	assert.NoError(t, err)
+	build, err := scheduler.WithClient(c).
+		WithResource("Dockerfile", []byte("FROM busybox\n")).
+		WithProperty(KanikoOptions, api.KanikoOptions{UseNewRun: &yes}).
+		Schedule(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, "1.8.1", build.Status.ExecutorVersion)
+	for i := 0; i < 2; i++ {
+		build, err = FromBuild(build).WithClient(c).Reconcile(context.TODO())
+		assert.NoError(t, err)
+	}
+	pod, err := getBuilderPod(context.TODO(), c, build)
+	assert.NoError(t, err)
+	assert.Equal(t, host+"/kaniko/executor:latest", pod.Spec.Containers[0].Image)
+
+	// the default version is assumed when neither the tag nor the labels tell it
+	build.Status.ExecutorVersion = ""
+	kanikoTask(build).ExecutorImage = "mirror.example.com/kaniko/executor@sha256:" + strings.Repeat("0", 64)
+	assert.Equal(t, defaults.KanikoVersion, kanikoVersion(build).String())
+}
+
+func validateKanikoOptionsError(t *testing.T, build *api.Build, kaniko string) string {
+	errs, _ := validateKanikoOptions(build, version.Must(version.NewVersion(kaniko)))
+	if len(errs) == 0 {
+		return ""
+	}
+	return errs.ToAggregate().Error()
+}
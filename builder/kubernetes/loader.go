@@ -0,0 +1,197 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kiegroup/container-builder/api"
+)
+
+// LoadPlatformBuilds decodes the PlatformBuild documents read from r, either JSON or YAML with one or more documents.
+// Defaults are applied to every PlatformBuild, then it's validated. Errors report the path of the offending fields.
+func LoadPlatformBuilds(r io.Reader) ([]api.PlatformBuild, error) {
+	var platforms []api.PlatformBuild
+	err := decodeDocuments(r, func(index int, document []byte) error {
+		platform := api.PlatformBuild{}
+		if err := yaml.UnmarshalStrict(document, &platform); err != nil {
+			return errors.Wrapf(err, "cannot decode PlatformBuild document %d", index)
+		}
+		setPlatformBuildDefaults(&platform)
+		if errs := platform.Validate(); len(errs) > 0 {
+			return errors.Wrapf(errs.ToAggregate(), "invalid PlatformBuild %s in document %d", platform.Name, index)
+		}
+		platforms = append(platforms, platform)
+		return nil
+	})
+	return platforms, err
+}
+
+// LoadBuilds decodes the Build documents read from r, either JSON or YAML with one or more documents.
+// Defaults are applied to every Build, then it's validated. Errors report the path of the offending fields.
+func LoadBuilds(r io.Reader) ([]api.Build, error) {
+	var builds []api.Build
+	err := decodeDocuments(r, func(index int, document []byte) error {
+		build := api.Build{}
+		if err := yaml.UnmarshalStrict(document, &build); err != nil {
+			return errors.Wrapf(err, "cannot decode Build document %d", index)
+		}
+		setBuildDefaults(&build)
+		if errs := build.Validate(); len(errs) > 0 {
+			return errors.Wrapf(errs.ToAggregate(), "invalid Build %s in document %d", build.Name, index)
+		}
+		builds = append(builds, build)
+		return nil
+	})
+	return builds, err
+}
+
+// NewBuilderInfo returns the BuilderInfo to schedule the given Build definition on the given platform.
+// The settings of the Build, like its timeout or its registry, take precedence over the platform ones.
+// An error is returned if the Build has no Kaniko task.
+func NewBuilderInfo(platform api.PlatformBuild, build api.Build) (BuilderInfo, error) {
+	kaniko := kanikoTask(&build)
+	if kaniko == nil {
+		return BuilderInfo{}, field.Required(field.NewPath("spec", "tasks"), "a Kaniko task")
+	}
+	platform = *platform.DeepCopy()
+	if len(build.Namespace) > 0 {
+		platform.Namespace = build.Namespace
+	}
+	if len(build.Spec.Strategy) > 0 && build.Spec.Strategy != platform.Spec.BuildStrategy {
+		return BuilderInfo{}, field.Invalid(field.NewPath("spec", "strategy"), build.Spec.Strategy,
+			"must match the build strategy of the PlatformBuild "+platform.Name)
+	}
+	if build.Spec.Timeout.Duration > 0 {
+		platform.Spec.Timeout = build.Spec.Timeout.DeepCopy()
+	}
+	if len(build.Spec.SecurityProfile) > 0 {
+		platform.Spec.SecurityProfile = build.Spec.SecurityProfile
+	}
+	if build.Spec.Job != nil {
+		platform.Spec.Job = build.Spec.Job.DeepCopy()
+	}
+	if build.Spec.InputCache {
+		platform.Spec.InputCache = true
+	}
+	if build.Spec.PinBaseImages {
+		platform.Spec.PinBaseImages = true
+	}
+	if len(kaniko.BaseImage) > 0 {
+		platform.Spec.BaseImage = kaniko.BaseImage
+	}
+	platform.Spec.Registry = mergeRegistry(platform.Spec.Registry, kaniko.Registry)
+	if len(kaniko.ExecutorImage) > 0 {
+		platform.Spec.ExecutorImage = kaniko.ExecutorImage
+	}
+	return BuilderInfo{
+		FinalImageName:  kaniko.Image,
+		BuildUniqueName: build.Name,
+		Platform:        platform,
+	}, nil
+}
+
+// mergeRegistry returns the registry of the platform overridden by the one of the task: the task address comes with its own
+// credentials and organization, while the mirrors of the platform are kept unless the task sets its own
+func mergeRegistry(platform api.RegistrySpec, task api.RegistrySpec) api.RegistrySpec {
+	registry := platform
+	if len(task.Address) > 0 {
+		registry = *task.DeepCopy()
+		registry.Mirrors = platform.Mirrors
+	}
+	if len(task.Mirrors) > 0 {
+		registry.Mirrors = task.DeepCopy().Mirrors
+	}
+	return registry
+}
+
+// NewBuildFromDefinition returns the Scheduler of the given Build definition on the given platform,
+// configured with the resources, the options, the additional flags, the build arguments and secrets and the cache of its Kaniko task.
+func NewBuildFromDefinition(platform api.PlatformBuild, build api.Build) (Scheduler, error) {
+	info, err := NewBuilderInfo(platform, build)
+	if err != nil {
+		return nil, err
+	}
+	kaniko := kanikoTask(&build)
+	scheduler, err := NewBuild(info)
+	if err != nil {
+		return nil, err
+	}
+	scheduler.WithResourceRequirements(kaniko.Resources).
+		WithAdditionalArgs(kaniko.AdditionalFlags).
+		WithBuildArgs(kaniko.BuildArgs).
+		WithBuildSecrets(kaniko.BuildSecrets).
+		WithProperty(KanikoOptions, kaniko.Options)
+	if kaniko.Cache.Enabled != nil || len(kaniko.Cache.PersistentVolumeClaim) > 0 {
+		scheduler.WithProperty(KanikoCache, kaniko.Cache)
+	}
+	return scheduler, nil
+}
+
+// decodeDocuments calls decode for every non-empty document read from r
+func decodeDocuments(r io.Reader, decode func(index int, document []byte) error) error {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	for index := 0; ; {
+		document, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "cannot read document")
+		}
+		// documents holding only comments are skipped as well
+		if converted, err := yaml.YAMLToJSON(document); err != nil {
+			return errors.Wrapf(err, "cannot decode document %d", index)
+		} else if bytes.Equal(bytes.TrimSpace(converted), []byte("null")) {
+			continue
+		}
+		if err := decode(index, document); err != nil {
+			return err
+		}
+		index++
+	}
+}
+
+func setPlatformBuildDefaults(platform *api.PlatformBuild) {
+	if len(platform.Spec.BuildStrategy) == 0 {
+		platform.Spec.BuildStrategy = api.BuildStrategyPod
+	}
+	if len(platform.Spec.PublishStrategy) == 0 {
+		platform.Spec.PublishStrategy = api.PlatformBuildPublishStrategyKaniko
+	}
+	if platform.Spec.Timeout == nil {
+		timeout := platform.Spec.GetTimeout()
+		platform.Spec.Timeout = &timeout
+	}
+	platform.Spec.SecurityProfile = platform.Spec.GetSecurityProfile()
+}
+
+// setBuildDefaults the Build timeout, security profile and job are left empty, so the platform ones are used
+func setBuildDefaults(build *api.Build) {
+	for _, task := range build.Spec.Tasks {
+		if task.Kaniko != nil && len(task.Kaniko.Name) == 0 {
+			task.Kaniko.Name = "KanikoTask"
+		}
+	}
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/util/defaults"
+)
+
+func TestLoadExamples(t *testing.T) {
+	platformFile, err := os.Open("../../examples/api/PlatformBuild_usingKanikowithCache.yaml")
+	assert.NoError(t, err)
+	defer platformFile.Close()
+	platforms, err := LoadPlatformBuilds(platformFile)
+	assert.NoError(t, err)
+	assert.Len(t, platforms, 1)
+	assert.Equal(t, "platform-kaniko-using-cache", platforms[0].Name)
+	assert.Equal(t, api.BuildStrategyPod, platforms[0].Spec.BuildStrategy)
+	assert.Equal(t, defaults.BuildTimeout, platforms[0].Spec.Timeout.Duration)
+	assert.Equal(t, api.SecurityProfileBaseline, platforms[0].Spec.SecurityProfile)
+
+	buildFile, err := os.Open("../../examples/api/Build_usingKanikowithCacheAndCustomizations.yaml")
+	assert.NoError(t, err)
+	defer buildFile.Close()
+	builds, err := LoadBuilds(buildFile)
+	assert.NoError(t, err)
+	assert.Len(t, builds, 1)
+
+	platforms[0].Namespace = "test"
+	info, err := NewBuilderInfo(platforms[0], builds[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "quay.io/kiegroup/greetings:latest", info.FinalImageName)
+	assert.Equal(t, "build-kaniko-using-cache-and-customizations", info.BuildUniqueName)
+	assert.Equal(t, "test", info.Platform.Namespace)
+
+	scheduler, err := NewBuildFromDefinition(platforms[0], builds[0])
+	assert.NoError(t, err)
+	kaniko := scheduler.(*kanikoScheduler).KanikoTask
+	assert.Equal(t, builds[0].Spec.Tasks[0].Kaniko.AdditionalFlags, kaniko.AdditionalFlags)
+	assert.Equal(t, api.KanikoSnapshotModeRedo, kaniko.Options.SnapshotMode)
+	assert.True(t, *kaniko.Options.UseNewRun)
+	assert.Equal(t, "2Gi", kaniko.Resources.Limits.Memory().String())
+}
+
+func TestNewBuilderInfoRegistry(t *testing.T) {
+	mirrors := []api.RegistryMirror{{Registry: "docker.io", Endpoints: []api.MirrorEndpoint{{Location: "mirror.gcr.io"}}}}
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{Name: "platform"},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy: api.BuildStrategyPod,
+			Registry:      api.RegistrySpec{Address: "quay.io/kiegroup", Secret: "quay-secret", Mirrors: mirrors},
+		},
+	}
+	build := api.Build{
+		ObjectReference: api.ObjectReference{Name: "build"},
+		Spec: api.BuildSpec{Tasks: []api.Task{{Kaniko: &api.KanikoTask{
+			PublishTask: api.PublishTask{Image: "greetings:latest", Registry: api.RegistrySpec{Address: "registry.example.com", Insecure: true}},
+		}}}},
+	}
+
+	// the task registry replaces the platform one, except its mirrors
+	info, err := NewBuilderInfo(platform, build)
+	assert.NoError(t, err)
+	assert.Equal(t, api.RegistrySpec{Address: "registry.example.com", Insecure: true, Mirrors: mirrors}, info.Platform.Spec.Registry)
+
+	taskMirrors := []api.RegistryMirror{{Registry: "quay.io", Endpoints: []api.MirrorEndpoint{{Location: "quay-mirror.example.com"}}}}
+	build.Spec.Tasks[0].Kaniko.Registry = api.RegistrySpec{Mirrors: taskMirrors}
+	info, err = NewBuilderInfo(platform, build)
+	assert.NoError(t, err)
+	assert.Equal(t, api.RegistrySpec{Address: "quay.io/kiegroup", Secret: "quay-secret", Mirrors: taskMirrors}, info.Platform.Spec.Registry)
+}
+
+func TestNewBuilderInfoWithoutKanikoTask(t *testing.T) {
+	platform := api.PlatformBuild{ObjectReference: api.ObjectReference{Name: "platform"}}
+	for _, build := range []api.Build{
+		{ObjectReference: api.ObjectReference{Name: "no-task"}},
+		{ObjectReference: api.ObjectReference{Name: "no-kaniko"}, Spec: api.BuildSpec{Tasks: []api.Task{{}}}},
+	} {
+		_, err := NewBuilderInfo(platform, build)
+		assert.EqualError(t, err, "spec.tasks: Required value: a Kaniko task")
+		_, err = NewBuildFromDefinition(platform, build)
+		assert.Error(t, err)
+	}
+}
+
+func TestLoadMultipleDocuments(t *testing.T) {
+	platforms, err := LoadPlatformBuilds(strings.NewReader(`
+meta:
+  name: first
+---
+# only comments
+---
+{"meta": {"name": "second"}, "spec": {"timeout": "10m"}}
+`))
+	assert.NoError(t, err)
+	assert.Len(t, platforms, 2)
+	assert.Equal(t, "first", platforms[0].Name)
+	assert.Equal(t, "second", platforms[1].Name)
+	assert.Equal(t, "10m0s", platforms[1].Spec.Timeout.Duration.String())
+}
+
+func TestLoadInvalidDocuments(t *testing.T) {
+	_, err := LoadPlatformBuilds(strings.NewReader(`
+spec:
+  timeout: -1m
+  securityProfile: unconfined
+`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "meta.name: Required value")
+	assert.Contains(t, err.Error(), "spec.timeout: Invalid value")
+	assert.Contains(t, err.Error(), "spec.securityProfile: Unsupported value")
+
+	_, err = LoadBuilds(strings.NewReader(`
+meta:
+  name: build
+spec:
+  tasks:
+    - kaniko:
+        additionalFlags: ["--cache=true"]
+`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.tasks[0].kaniko.image: Required value")
+
+	_, err = LoadBuilds(strings.NewReader(`
+name: build
+`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown field "name"`)
+}
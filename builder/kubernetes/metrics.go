@@ -0,0 +1,164 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/kiegroup/container-builder/api"
+)
+
+const metricsNamespace = "container_builder"
+
+var (
+	buildsStarted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "builds_started_total",
+		Help:      "Number of builds started.",
+	}, []string{"strategy", "namespace"})
+	buildsSucceeded = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "builds_succeeded_total",
+		Help:      "Number of builds succeeded.",
+	}, []string{"strategy", "namespace"})
+	buildsFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "builds_failed_total",
+		Help:      "Number of builds failed, errored or interrupted, by final phase.",
+	}, []string{"strategy", "namespace", "phase"})
+	buildRecoveryAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "build_recovery_attempts_total",
+		Help:      "Number of attempts to recover failed builds.",
+	}, []string{"strategy", "namespace"})
+	buildPhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "build_phase_duration_seconds",
+		Help:      "Time spent by the builds in every phase.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+	}, []string{"strategy", "phase"})
+	buildTimeToPush = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "build_time_to_push_seconds",
+		Help:      "Time from the start of the builds to the end of the push of their image.",
+		Buckets:   []float64{15, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+	}, []string{"strategy", "namespace"})
+	buildsQueuedDesc = prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "builds_queued"),
+		"Number of builds waiting for their builder to run.", []string{"strategy", "namespace"}, nil)
+)
+
+// queuedPhases the phases of the builds waiting for their builder to run
+var queuedPhases = map[api.BuildPhase]bool{
+	api.BuildPhaseScheduling: true,
+	api.BuildPhasePending:    true,
+}
+
+// BuildLister lists the existing builds, see NewQueuedBuildsCollector
+type BuildLister func(ctx context.Context) ([]api.Build, error)
+
+// Collectors returns the Prometheus collectors of the builds metrics, updated upon every phase transition
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		buildsStarted,
+		buildsSucceeded,
+		buildsFailed,
+		buildRecoveryAttempts,
+		buildPhaseDuration,
+		buildTimeToPush,
+	}
+}
+
+// NewQueuedBuildsCollector returns the collector of the number of builds waiting for their builder to run.
+// It's computed from the builds returned by the lister upon every collection, so that the builds deleted while queued
+// or queued before the process started are accounted for.
+func NewQueuedBuildsCollector(list BuildLister) prometheus.Collector {
+	return &queuedBuildsCollector{list: list}
+}
+
+type queuedBuildsCollector struct {
+	list BuildLister
+}
+
+func (c *queuedBuildsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- buildsQueuedDesc
+}
+
+func (c *queuedBuildsCollector) Collect(ch chan<- prometheus.Metric) {
+	builds, err := c.list(context.Background())
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(buildsQueuedDesc, errors.Wrap(err, "cannot list the builds"))
+		return
+	}
+	type queue struct{ strategy, namespace string }
+	queued := map[queue]int{}
+	for _, build := range builds {
+		if queuedPhases[build.Status.Phase] {
+			queued[queue{string(build.Spec.Strategy), build.Namespace}]++
+		}
+	}
+	for q, count := range queued {
+		ch <- prometheus.MustNewConstMetric(buildsQueuedDesc, prometheus.GaugeValue, float64(count), q.strategy, q.namespace)
+	}
+}
+
+// RegisterMetrics registers the builds metrics, for example into the controller-runtime metrics.Registry.
+// Collectors already registered are ignored, so it can be called many times.
+func RegisterMetrics(registerer prometheus.Registerer) error {
+	for _, collector := range Collectors() {
+		if err := registerer.Register(collector); err != nil {
+			if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+				return errors.Wrap(err, "cannot register builds metrics")
+			}
+		}
+	}
+	return nil
+}
+
+// recordTransitionMetrics updates the builds metrics with the last phase transition recorded in the build status
+func recordTransitionMetrics(from api.BuildPhase, build *api.Build) {
+	strategy := string(build.Spec.Strategy)
+	to := build.Status.Phase
+
+	switch {
+	case from == api.BuildPhaseNone:
+		buildsStarted.WithLabelValues(strategy, build.Namespace).Inc()
+	case from == api.BuildPhaseFailed && to == api.BuildPhaseInitialization:
+		buildRecoveryAttempts.WithLabelValues(strategy, build.Namespace).Inc()
+	}
+
+	switch to {
+	case api.BuildPhaseSucceeded:
+		buildsSucceeded.WithLabelValues(strategy, build.Namespace).Inc()
+		// the builds reusing an image built from the same inputs don't push any
+		if timings := build.Status.Timings; build.Status.StartedAt != nil && timings != nil && timings.FinishedAt != nil {
+			buildTimeToPush.WithLabelValues(strategy, build.Namespace).Observe(timings.FinishedAt.Sub(build.Status.StartedAt.Time).Seconds())
+		}
+	case api.BuildPhaseError, api.BuildPhaseInterrupted:
+		buildsFailed.WithLabelValues(strategy, build.Namespace, string(to)).Inc()
+	}
+
+	// the time spent in the previous phase, known if the build entered it while tracking the transitions
+	if transitions := build.Status.Transitions; len(transitions) > 1 && from != api.BuildPhaseNone {
+		entered := transitions[len(transitions)-2]
+		if entered.To == from {
+			buildPhaseDuration.WithLabelValues(strategy, string(from)).Observe(transitions[len(transitions)-1].Time.Sub(entered.Time.Time).Seconds())
+		}
+	}
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/util/test"
+)
+
+func TestBuildMetrics(t *testing.T) {
+	ns := "metrics"
+	c, err := test.NewFakeClient()
+	assert.NoError(t, err)
+
+	registry := prometheus.NewRegistry()
+	assert.NoError(t, RegisterMetrics(registry))
+	// registering twice is harmless
+	assert.NoError(t, RegisterMetrics(registry))
+
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{
+			Namespace: ns,
+			Name:      "testPlatform",
+		},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy:   api.BuildStrategyPod,
+			PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+			Timeout:         &metav1.Duration{Duration: 5 * time.Minute},
+		},
+	}
+	scheduler, err := NewBuild(BuilderInfo{FinalImageName: "quay.io/kiegroup/buildexample:latest", BuildUniqueName: "build1", Platform: platform})
+	assert.NoError(t, err)
+	build, err := scheduler.WithClient(c).Schedule(context.TODO())
+	assert.NoError(t, err)
+
+	strategy := string(api.BuildStrategyPod)
+	assert.Equal(t, float64(1), testutil.ToFloat64(buildsStarted.WithLabelValues(strategy, ns)))
+
+	build, err = FromBuild(build).WithClient(c).Reconcile(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, api.BuildPhasePending, build.Status.Phase)
+
+	_, err = FromBuild(build).WithClient(c).CancelBuild(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(buildsFailed.WithLabelValues(strategy, ns, string(api.BuildPhaseInterrupted))))
+	assert.Equal(t, float64(0), testutil.ToFloat64(buildsSucceeded.WithLabelValues(strategy, ns)))
+
+	count, err := testutil.GatherAndCount(registry, "container_builder_build_phase_duration_seconds")
+	assert.NoError(t, err)
+	assert.NotZero(t, count)
+}
+
+func TestQueuedBuildsMetrics(t *testing.T) {
+	builds := []api.Build{
+		{ObjectReference: api.ObjectReference{Namespace: "ns1", Name: "scheduling"}, Spec: api.BuildSpec{Strategy: api.BuildStrategyPod},
+			Status: api.BuildStatus{Phase: api.BuildPhaseScheduling}},
+		{ObjectReference: api.ObjectReference{Namespace: "ns1", Name: "pending"}, Spec: api.BuildSpec{Strategy: api.BuildStrategyPod},
+			Status: api.BuildStatus{Phase: api.BuildPhasePending}},
+		{ObjectReference: api.ObjectReference{Namespace: "ns2", Name: "pending"}, Spec: api.BuildSpec{Strategy: api.BuildStrategyPod},
+			Status: api.BuildStatus{Phase: api.BuildPhasePending}},
+		{ObjectReference: api.ObjectReference{Namespace: "ns2", Name: "running"}, Spec: api.BuildSpec{Strategy: api.BuildStrategyPod},
+			Status: api.BuildStatus{Phase: api.BuildPhaseRunning}},
+	}
+	// the queue depth follows the existing builds, like the ones deleted while queued
+	collector := NewQueuedBuildsCollector(func(ctx context.Context) ([]api.Build, error) {
+		return builds, nil
+	})
+	expected := `
+# HELP container_builder_builds_queued Number of builds waiting for their builder to run.
+# TYPE container_builder_builds_queued gauge
+container_builder_builds_queued{namespace="ns1",strategy="pod"} 2
+container_builder_builds_queued{namespace="ns2",strategy="pod"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+
+	builds = builds[1:2]
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector))
+}
+
+func TestTimeToPushMetrics(t *testing.T) {
+	ns := "push"
+	startedAt := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+	finishedAt := metav1.NewTime(startedAt.Add(4 * time.Minute))
+	build := &api.Build{
+		ObjectReference: api.ObjectReference{Namespace: ns, Name: "pushed"},
+		Spec:            api.BuildSpec{Strategy: api.BuildStrategyPod},
+		Status: api.BuildStatus{
+			Phase:     api.BuildPhaseSucceeded,
+			StartedAt: &startedAt,
+			Timings:   &api.BuildTimings{FinishedAt: &finishedAt},
+		},
+	}
+	recordTransitionMetrics(api.BuildPhaseRunning, build)
+	// the builds reusing an image don't push it
+	reused := &api.Build{
+		ObjectReference: api.ObjectReference{Namespace: ns, Name: "reused"},
+		Spec:            api.BuildSpec{Strategy: api.BuildStrategyPod},
+		Status:          api.BuildStatus{Phase: api.BuildPhaseSucceeded, StartedAt: &startedAt},
+	}
+	recordTransitionMetrics(api.BuildPhaseScheduling, reused)
+
+	expected := `
+# HELP container_builder_build_time_to_push_seconds Time from the start of the builds to the end of the push of their image.
+# TYPE container_builder_build_time_to_push_seconds histogram
+container_builder_build_time_to_push_seconds_bucket{namespace="push",strategy="pod",le="15"} 0
+container_builder_build_time_to_push_seconds_bucket{namespace="push",strategy="pod",le="30"} 0
+container_builder_build_time_to_push_seconds_bucket{namespace="push",strategy="pod",le="60"} 0
+container_builder_build_time_to_push_seconds_bucket{namespace="push",strategy="pod",le="120"} 0
+container_builder_build_time_to_push_seconds_bucket{namespace="push",strategy="pod",le="300"} 1
+container_builder_build_time_to_push_seconds_bucket{namespace="push",strategy="pod",le="600"} 1
+container_builder_build_time_to_push_seconds_bucket{namespace="push",strategy="pod",le="1200"} 1
+container_builder_build_time_to_push_seconds_bucket{namespace="push",strategy="pod",le="1800"} 1
+container_builder_build_time_to_push_seconds_bucket{namespace="push",strategy="pod",le="3600"} 1
+container_builder_build_time_to_push_seconds_bucket{namespace="push",strategy="pod",le="+Inf"} 1
+container_builder_build_time_to_push_seconds_sum{namespace="push",strategy="pod"} 240
+container_builder_build_time_to_push_seconds_count{namespace="push",strategy="pod"} 1
+`
+	registry := prometheus.NewRegistry()
+	assert.NoError(t, registry.Register(buildTimeToPush))
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "container_builder_build_time_to_push_seconds"))
+}
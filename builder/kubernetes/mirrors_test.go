@@ -0,0 +1,88 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/util/test"
+)
+
+func TestRegistryMirrors(t *testing.T) {
+	ns := "test"
+	c, err := test.NewFakeClient()
+	assert.NoError(t, err)
+	mirrors := []api.RegistryMirror{
+		{Registry: "docker.io", Endpoints: []api.MirrorEndpoint{{Location: "cache.example.com:5000/docker-hub", Insecure: true}, {Location: "mirror.gcr.io"}}},
+		{Registry: "quay.io", Endpoints: []api.MirrorEndpoint{{Location: "cache.example.com:5000/quay"}, {Location: "quay-mirror.example.com"}}},
+	}
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{Namespace: ns, Name: "testPlatform"},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy:   api.BuildStrategyPod,
+			PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+			Registry:        api.RegistrySpec{Mirrors: mirrors},
+		},
+	}
+
+	// the mirrors of the registries other than the Docker Hub require a recent Kaniko
+	scheduler, err := NewBuild(BuilderInfo{FinalImageName: "quay.io/kiegroup/buildexample:latest", BuildUniqueName: "mirrors", Platform: platform})
+	assert.NoError(t, err)
+	_, err = scheduler.WithClient(c).WithResource("Dockerfile", []byte("FROM busybox\n")).Schedule(context.TODO())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `spec.tasks[0].kaniko.registry.mirrors[1].registry: Invalid value: "quay.io": only the Docker Hub mirrors are supported by Kaniko 1.9.0`)
+	assert.NotContains(t, err.Error(), "mirrors[0]")
+
+	platform.Spec.ExecutorImage = "gcr.io/kaniko-project/executor:v1.10.0"
+	scheduler, err = NewBuild(BuilderInfo{FinalImageName: "quay.io/kiegroup/buildexample:latest", BuildUniqueName: "mirrors", Platform: platform})
+	assert.NoError(t, err)
+	build, err := scheduler.WithClient(c).WithResource("Dockerfile", []byte("FROM busybox\n")).Schedule(context.TODO())
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		build, err = FromBuild(build).WithClient(c).Reconcile(context.TODO())
+		assert.NoError(t, err)
+	}
+	pod, err := getBuilderPod(context.TODO(), c, build)
+	assert.NoError(t, err)
+	assert.Subset(t, pod.Spec.Containers[0].Args, []string{
+		"--registry-mirror=cache.example.com:5000/docker-hub",
+		"--registry-mirror=mirror.gcr.io",
+		"--registry-map=quay.io=cache.example.com:5000/quay;quay-mirror.example.com",
+		"--insecure-registry=cache.example.com:5000",
+	})
+
+}
+
+func TestValidateRegistryMirrors(t *testing.T) {
+	spec := api.RegistrySpec{Mirrors: []api.RegistryMirror{
+		{Registry: "docker.io", Endpoints: []api.MirrorEndpoint{{Location: "https://mirror.example.com"}, {Location: "mirror"}, {}}},
+		{Registry: "docker.io/library"},
+		{Registry: "docker.io", Endpoints: []api.MirrorEndpoint{{Location: "localhost:5000"}}},
+	}}
+	errs := spec.Validate(nil).ToAggregate().Error()
+	assert.Contains(t, errs, `mirrors[0].endpoints[0].location: Invalid value: "https://mirror.example.com": must not have a scheme`)
+	assert.Contains(t, errs, `mirrors[0].endpoints[1].location: Invalid value: "mirror": must be a registry host`)
+	assert.Contains(t, errs, "mirrors[0].endpoints[2].location: Required value")
+	assert.Contains(t, errs, `mirrors[1].registry: Invalid value: "docker.io/library": must be a registry host`)
+	assert.Contains(t, errs, "mirrors[1].endpoints: Required value")
+	assert.Contains(t, errs, `mirrors[2].registry: Duplicate value: "docker.io"`)
+	assert.NotContains(t, errs, "mirrors[2].endpoints")
+}
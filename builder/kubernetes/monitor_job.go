@@ -0,0 +1,157 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func newMonitorJobAction() Action {
+	return &monitorJobAction{}
+}
+
+// monitorJobAction monitors builds executed by a Kubernetes Job.
+// The Job status is the source of truth: a failed Pod doesn't fail the build while the Job can still retry it.
+type monitorJobAction struct {
+	monitorPodAction
+}
+
+// Name returns a common name of the action.
+func (action *monitorJobAction) Name() string {
+	return "monitor-job"
+}
+
+// CanHandle tells whether this action can handle the build.
+func (action *monitorJobAction) CanHandle(build *api.Build) bool {
+	return build.Spec.Job != nil && (build.Status.Phase == api.BuildPhasePending || build.Status.Phase == api.BuildPhaseRunning)
+}
+
+func (action *monitorJobAction) Handle(ctx context.Context, build *api.Build) (*api.Build, error) {
+	job, err := getBuilderJob(ctx, action.client, build)
+	if err != nil {
+		return nil, err
+	}
+
+	if job == nil {
+		switch build.Status.Phase {
+
+		case api.BuildPhasePending:
+			if job, err = newBuildJob(ctx, action.client, build); err != nil {
+				return nil, err
+			}
+			if err = action.client.Create(ctx, job); err != nil {
+				return nil, errors.Wrap(err, "cannot create build job")
+			}
+			return build, nil
+
+		case api.BuildPhaseRunning:
+			// Emulate context cancellation
+			build.Status.Phase = api.BuildPhaseInterrupted
+			build.Status.Error = "Job deleted"
+			return build, nil
+		}
+	}
+
+	if job.DeletionTimestamp != nil {
+		build.Status.Phase = api.BuildPhaseInterrupted
+		build.Status.Error = "Job deleted"
+		return build, nil
+	}
+
+	pod, err := getBuilderPod(ctx, action.client, build)
+	if err != nil {
+		return nil, err
+	}
+
+	if condition := action.getJobCondition(job, batchv1.JobComplete); condition != nil {
+		build.Status.Phase = api.BuildPhaseSucceeded
+		finishedAt := condition.LastTransitionTime
+		if job.Status.CompletionTime != nil {
+			finishedAt = *job.Status.CompletionTime
+		}
+		build.Status.Duration = finishedAt.Sub(build.Status.StartedAt.Time).String()
+		setFinishedTimings(build, pod, finishedAt)
+		if pod != nil {
+			action.setProgress(ctx, build, pod)
+		}
+
+		for _, task := range build.Spec.Tasks {
+			if t := task.Kaniko; t != nil {
+				build.Status.Image = t.Image
+				break
+			}
+		}
+		return build, nil
+	}
+
+	if condition := action.getJobCondition(job, batchv1.JobFailed); condition != nil {
+		phase := api.BuildPhaseFailed
+		message := "Job failed"
+		if pod != nil {
+			if terminationMessage := action.getTerminationMessage(pod); terminationMessage != "" {
+				message = terminationMessage
+			}
+		}
+		switch condition.Reason {
+		case "DeadlineExceeded":
+			message = buildTimeoutError
+		case "BackoffLimitExceeded":
+			// the termination message of the last Pod is more meaningful
+		default:
+			if condition.Message != "" {
+				message = condition.Message
+			}
+		}
+		// Do not override errored build
+		if build.Status.Phase == api.BuildPhaseError {
+			phase = api.BuildPhaseError
+		}
+		build.Status.Phase = phase
+		build.Status.Error = message
+		build.Status.Duration = condition.LastTransitionTime.Sub(build.Status.StartedAt.Time).String()
+		setFinishedTimings(build, pod, condition.LastTransitionTime)
+		if pod != nil {
+			action.setProgress(ctx, build, pod)
+		}
+		return build, nil
+	}
+
+	// Pod remains in pending phase when init containers execute, a failed pod might be replaced by the Job controller
+	if pod != nil && pod.Status.Phase != corev1.PodFailed {
+		setPodTimings(build, pod)
+		action.setProgress(ctx, build, pod)
+		if action.isPodScheduled(pod) {
+			build.Status.Phase = api.BuildPhaseRunning
+		}
+	}
+
+	return build, nil
+}
+
+func (action *monitorJobAction) getJobCondition(job *batchv1.Job, conditionType batchv1.JobConditionType) *batchv1.JobCondition {
+	for i := range job.Status.Conditions {
+		if job.Status.Conditions[i].Type == conditionType && job.Status.Conditions[i].Status == corev1.ConditionTrue {
+			return &job.Status.Conditions[i]
+		}
+	}
+	return nil
+}
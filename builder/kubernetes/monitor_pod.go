@@ -22,9 +22,6 @@ import (
 	"os"
 	"time"
 
-	"github.com/hashicorp/go-version"
-	"github.com/kiegroup/container-builder/util/defaults"
-
 	"github.com/kiegroup/container-builder/api"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -41,7 +38,7 @@ func newMonitorPodAction() Action {
 }
 
 type monitorPodAction struct {
-	baseAction
+	BaseAction
 }
 
 // Name returns a common name of the action.
@@ -51,7 +48,7 @@ func (action *monitorPodAction) Name() string {
 
 // CanHandle tells whether this action can handle the build.
 func (action *monitorPodAction) CanHandle(build *api.Build) bool {
-	return build.Status.Phase == api.BuildPhasePending || build.Status.Phase == api.BuildPhaseRunning
+	return build.Spec.Job == nil && (build.Status.Phase == api.BuildPhasePending || build.Status.Phase == api.BuildPhaseRunning)
 }
 
 func (action *monitorPodAction) Handle(ctx context.Context, build *api.Build) (*api.Build, error) {
@@ -67,11 +64,12 @@ func (action *monitorPodAction) Handle(ctx context.Context, build *api.Build) (*
 			if pod, err = newBuildPod(ctx, action.client, build); err != nil {
 				return nil, err
 			}
-			// TODO: every object we create, must pass to a listener for our client code. For example, an operator would like to add their labels/owner refs
 
 			if err = action.client.Create(ctx, pod); err != nil {
 				return nil, errors.Wrap(err, "cannot create build pod")
 			}
+			// the pod status is checked upon the next reconciliation
+			return build, nil
 
 		case api.BuildPhaseRunning:
 			// Emulate context cancellation
@@ -84,6 +82,8 @@ func (action *monitorPodAction) Handle(ctx context.Context, build *api.Build) (*
 	switch pod.Status.Phase {
 
 	case corev1.PodPending, corev1.PodRunning:
+		setPodTimings(build, pod)
+		action.setProgress(ctx, build, pod)
 		// Pod remains in pending phase when init containers execute
 		if action.isPodScheduled(pod) {
 			build.Status.Phase = api.BuildPhaseRunning
@@ -96,9 +96,7 @@ func (action *monitorPodAction) Handle(ctx context.Context, build *api.Build) (*
 			}
 			// In latest Kaniko versions kill is no more available in image's $PATH, do we still need it?
 			// Send SIGTERM signal to running containers
-			current, err := version.NewVersion(defaults.KanikoVersion)
-			maxVersionSupportingKill, err := version.NewVersion(defaults.KanikoVersionSupportingKill)
-			if current.LessThanOrEqual(maxVersionSupportingKill) {
+			if kanikoKillFeature.supportedBy(kanikoVersion(build)) {
 				if err = action.sigterm(pod); err != nil {
 					// Requeue
 					return nil, err
@@ -116,6 +114,8 @@ func (action *monitorPodAction) Handle(ctx context.Context, build *api.Build) (*
 		finishedAt := action.getTerminatedTime(pod)
 		duration := finishedAt.Sub(build.Status.StartedAt.Time)
 		build.Status.Duration = duration.String()
+		setFinishedTimings(build, pod, finishedAt)
+		action.setProgress(ctx, build, pod)
 
 		for _, task := range build.Spec.Tasks {
 			if t := task.Kaniko; t != nil {
@@ -134,7 +134,7 @@ func (action *monitorPodAction) Handle(ctx context.Context, build *api.Build) (*
 			phase = api.BuildPhaseInterrupted
 			message = "Pod deleted"
 		} else if _, ok := pod.GetAnnotations()[timeoutAnnotation]; ok {
-			message = "Build timeout"
+			message = buildTimeoutError
 		}
 		// Do not override errored build
 		if build.Status.Phase == api.BuildPhaseError {
@@ -145,6 +145,8 @@ func (action *monitorPodAction) Handle(ctx context.Context, build *api.Build) (*
 		finishedAt := action.getTerminatedTime(pod)
 		duration := finishedAt.Sub(build.Status.StartedAt.Time)
 		build.Status.Duration = duration.String()
+		setFinishedTimings(build, pod, finishedAt)
+		action.setProgress(ctx, build, pod)
 	}
 
 	return build, nil
@@ -31,9 +31,12 @@ import (
 // addResourcesToVolume add to the given volumes the build resources context. The resources reference must be previously created.
 func addResourcesToVolume(ctx context.Context, client client.Client, task api.PublishTask, build *api.Build, volumes *[]corev1.Volume, volumeMounts *[]corev1.VolumeMount) error {
 	// TODO: do it via specialized handlers
+	if build.Status.ResourceVolume == nil {
+		return errors.Errorf("no resources context defined for build %s in ns %s", build.Name, build.Namespace)
+	}
 	switch build.Status.ResourceVolume.ReferenceType {
 	case api.ResourceReferenceTypeConfigMap:
-		configMap, err := getResourcesConfigMap(ctx, client, build)
+		configMap, err := getResourcesConfigMap(ctx, client, build.Namespace, build.Status.ResourceVolume.ReferenceName)
 		if err != nil {
 			return err
 		}
@@ -74,7 +77,7 @@ func addResourcesToVolume(ctx context.Context, client client.Client, task api.Pu
 }
 
 // TODO: create an actual handler for resources build context. For PoC level, CM will do
-func mountResourcesWithConfigMap(buildContext *buildContext, resources *[]resource) error {
+func mountResourcesWithConfigMap(buildContext *BuildContext, resources *[]resource) error {
 	configMap, err := getOrCreateResourcesConfigMap(buildContext, resources)
 	if err != nil {
 		return err
@@ -88,9 +91,9 @@ func mountResourcesWithConfigMap(buildContext *buildContext, resources *[]resour
 	return nil
 }
 
-func getResourcesConfigMap(c context.Context, client client.Client, build *api.Build) (*corev1.ConfigMap, error) {
+func getResourcesConfigMap(c context.Context, client client.Client, namespace, name string) (*corev1.ConfigMap, error) {
 	resourcesConfigMap := corev1.ConfigMap{}
-	configMapId := types.NamespacedName{Name: buildPodName(build), Namespace: build.Namespace}
+	configMapId := types.NamespacedName{Name: name, Namespace: namespace}
 
 	if err := client.Get(c, configMapId, &resourcesConfigMap); err != nil {
 		if k8serrors.IsNotFound(err) {
@@ -102,9 +105,9 @@ func getResourcesConfigMap(c context.Context, client client.Client, build *api.B
 	return &resourcesConfigMap, nil
 }
 
-func getOrCreateResourcesConfigMap(buildContext *buildContext, resources *[]resource) (*corev1.ConfigMap, error) {
+func getOrCreateResourcesConfigMap(buildContext *BuildContext, resources *[]resource) (*corev1.ConfigMap, error) {
 	// TODO: build an actual configMap builder context handler
-	resourcesConfigMap, err := getResourcesConfigMap(buildContext.C, buildContext.Client, buildContext.Build)
+	resourcesConfigMap, err := getResourcesConfigMap(buildContext.C, buildContext.Client, buildContext.Build.Namespace, buildPodName(buildContext.Build))
 	if err != nil {
 		return nil, err
 	}
@@ -115,8 +118,7 @@ func getOrCreateResourcesConfigMap(buildContext *buildContext, resources *[]reso
 		resourcesConfigMap.Namespace = configMapId.Namespace
 		resourcesConfigMap.Name = configMapId.Name
 		addContentToConfigMap(resourcesConfigMap, resources)
-		// TODO: every object we create, must pass to a listener for our client code. For example, an operator would like to add their labels/owner refs
-		if err := buildContext.Client.Create(buildContext.C, resourcesConfigMap); err != nil {
+		if err := buildContext.DecoratedClient().Create(buildContext.C, resourcesConfigMap); err != nil {
 			return nil, err
 		}
 	} else {
@@ -0,0 +1,235 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiegroup/container-builder/api"
+)
+
+// dockerfileName the name of the Dockerfile in the build context, given to Kaniko
+const dockerfileName = "Dockerfile"
+
+var (
+	// kanikoLogPattern matches the Kaniko log entries, like "INFO[0010] RUN mvn package", telling the elapsed seconds
+	kanikoLogPattern = regexp.MustCompile(`^[A-Z]{4}\[\d+\]\s*(.*)$`)
+	// colorPattern matches the ANSI escape sequences coloring the Kaniko output
+	colorPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+)
+
+// dockerfileInstructions the Dockerfile instructions logged by Kaniko when it executes them.
+// FROM starts a new stage, it's not executed.
+var dockerfileInstructions = map[string]bool{
+	"ADD":         true,
+	"ARG":         true,
+	"CMD":         true,
+	"COPY":        true,
+	"ENTRYPOINT":  true,
+	"ENV":         true,
+	"EXPOSE":      true,
+	"HEALTHCHECK": true,
+	"LABEL":       true,
+	"MAINTAINER":  true,
+	"ONBUILD":     true,
+	"RUN":         true,
+	"SHELL":       true,
+	"STOPSIGNAL":  true,
+	"USER":        true,
+	"VOLUME":      true,
+	"WORKDIR":     true,
+}
+
+// kanikoSteps the beginning of the Kaniko messages telling it moved to another step, besides the Dockerfile instructions
+var kanikoSteps = []string{
+	"Retrieving image",
+	"Taking snapshot",
+	kanikoPushMessage,
+	"Pushed ",
+}
+
+// builderOutputLimit the maximum number of bytes of the builder output read by every reconciliation, the rest being read by the next ones
+const builderOutputLimit int64 = 1 << 20
+
+// setProgress updates the progress of the build from the output of its builder container, once it started, as well as the time
+// the image push started. The output is read incrementally, from the time of the last activity on.
+func (action *monitorPodAction) setProgress(ctx context.Context, build *api.Build, pod *corev1.Pod) {
+	if status := builderContainerStatus(build, pod); status == nil || (status.State.Running == nil && status.State.Terminated == nil) {
+		return
+	}
+	progress := build.Status.Progress.DeepCopy()
+	if progress == nil {
+		progress = &api.BuildProgress{}
+	}
+	output := action.getBuilderOutput(ctx, build, pod, progress.LastActivityAt)
+	if output == nil {
+		return
+	}
+	pushStartedAt := parseKanikoProgress(bytes.NewReader(output), progress)
+	if *progress != (api.BuildProgress{}) {
+		build.Status.Progress = progress
+	}
+	if timings := buildTimings(build); pushStartedAt != nil && timings.PushStartedAt == nil {
+		timings.PushStartedAt = pushStartedAt
+		timings.SetDurations()
+	}
+}
+
+// getBuilderOutput returns the complete lines of the output of the Kaniko container logged since the given time, if any,
+// each line prefixed with its timestamp. At most builderOutputLimit bytes are read.
+// Nil is returned when the output can't be read, the build goes on without it.
+func (action *monitorPodAction) getBuilderOutput(ctx context.Context, build *api.Build, pod *corev1.Pod, since *metav1.Time) []byte {
+	container := builderContainerName(build)
+	if len(container) == 0 {
+		return nil
+	}
+	limit := builderOutputLimit
+	logs, err := action.client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container:  container,
+		Timestamps: true,
+		SinceTime:  since,
+		LimitBytes: &limit,
+	}).Stream(ctx)
+	if err != nil {
+		action.L.Errorf(err, "Cannot read the output of the builder pod %s", pod.Name)
+		return nil
+	}
+	defer logs.Close()
+	output, err := io.ReadAll(io.LimitReader(logs, limit))
+	if err != nil {
+		action.L.Errorf(err, "Cannot read the output of the builder pod %s", pod.Name)
+		return nil
+	}
+	if int64(len(output)) == limit {
+		// the last line is cut, it's read again by the next reconciliation
+		output = output[:bytes.LastIndexByte(output, '\n')+1]
+	}
+	return output
+}
+
+// parseKanikoProgress updates the current step of the progress from the Kaniko output, as well as the time of its last line.
+// The output starts at the second of the last activity, whose LastActivityLines lines were already parsed.
+// It returns the time of the output line telling Kaniko started to push the image, if any.
+func parseKanikoProgress(r io.Reader, progress *api.BuildProgress) *metav1.Time {
+	var pushStartedAt *metav1.Time
+	var lastActivity time.Time
+	var lastSecond time.Time
+	if progress.LastActivityAt != nil {
+		lastSecond = progress.LastActivityAt.Truncate(time.Second)
+	}
+	// the lines of the last second already parsed
+	skip := progress.LastActivityLines
+	lines := progress.LastActivityLines
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		timestamp, message, ok := parseKanikoLogLine(scanner.Text())
+		if !timestamp.IsZero() {
+			if second := timestamp.Truncate(time.Second); second.Equal(lastSecond) {
+				if skip > 0 {
+					skip--
+					continue
+				}
+				lines++
+			} else {
+				lastSecond, lines, skip = second, 1, 0
+			}
+			lastActivity = timestamp
+		}
+		if !ok {
+			// the output of the commands run by the Dockerfile instructions
+			continue
+		}
+		if instruction, _, _ := strings.Cut(message, " "); dockerfileInstructions[instruction] {
+			progress.Step = message
+			progress.StepNumber++
+			continue
+		}
+		for _, prefix := range kanikoSteps {
+			if strings.HasPrefix(message, prefix) {
+				progress.Step = message
+				break
+			}
+		}
+		if pushStartedAt == nil && !timestamp.IsZero() && strings.HasPrefix(message, kanikoPushMessage) {
+			pushStartedAt = &metav1.Time{Time: timestamp}
+		}
+	}
+
+	if !lastActivity.IsZero() {
+		lastActivityAt := metav1.NewTime(lastActivity)
+		progress.LastActivityAt = &lastActivityAt
+		progress.LastActivityLines = lines
+	}
+	return pushStartedAt
+}
+
+// parseKanikoLogLine splits a line of the Pod logs into its timestamp and its message, true if it's a Kaniko log entry.
+// The timestamp is zero if the line doesn't start with it.
+func parseKanikoLogLine(line string) (time.Time, string, bool) {
+	timestamp, text, found := strings.Cut(line, " ")
+	if !found {
+		return time.Time{}, line, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return time.Time{}, line, false
+	}
+	text = colorPattern.ReplaceAllString(text, "")
+	if match := kanikoLogPattern.FindStringSubmatch(text); match != nil {
+		return t, match[1], true
+	}
+	return t, text, false
+}
+
+// countDockerfileSteps returns the number of instructions executed by Kaniko for the given Dockerfile
+func countDockerfileSteps(dockerfile []byte) int {
+	steps := 0
+	inStage := false
+	continued := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(dockerfile))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			// comments and empty lines don't break the continued instructions
+			continue
+		}
+		wasContinued := continued
+		continued = strings.HasSuffix(line, `\`)
+		if wasContinued {
+			continue
+		}
+		instruction := strings.ToUpper(strings.Fields(line)[0])
+		if instruction == "FROM" {
+			inStage = true
+		} else if inStage && dockerfileInstructions[instruction] {
+			// the ARGs before the first FROM are not executed
+			steps++
+		}
+	}
+	return steps
+}
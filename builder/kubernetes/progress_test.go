@@ -0,0 +1,105 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/util/test"
+)
+
+func TestCountDockerfileSteps(t *testing.T) {
+	dockerFile, err := os.ReadFile("testdata/Dockerfile")
+	assert.NoError(t, err)
+	assert.Equal(t, 14, countDockerfileSteps(dockerFile))
+
+	assert.Equal(t, 2, countDockerfileSteps([]byte(`ARG VERSION=latest
+FROM quay.io/kiegroup/kogito-swf-builder:${VERSION}
+RUN mvn clean \
+    # a comment in a continued instruction
+    install
+copy . /deployments
+`)))
+}
+
+func TestParseKanikoProgress(t *testing.T) {
+	output := "2023-01-10T10:00:00Z \x1b[36mINFO\x1b[0m[0000] Retrieving image manifest quay.io/kiegroup/kogito-swf-builder-nightly:latest\n" +
+		"2023-01-10T10:00:05Z \x1b[36mINFO\x1b[0m[0005] USER 1001\n" +
+		"2023-01-10T10:00:06Z \x1b[36mINFO\x1b[0m[0006] RUN ${MAVEN_HOME}/bin/mvn clean install\n" +
+		"2023-01-10T10:00:07Z [INFO] COPY of the Maven output isn't a step\n" +
+		"2023-01-10T10:01:30.100Z \x1b[36mINFO\x1b[0m[0090] ENV JAVA_OPTS=-Xmx1g\n" +
+		"2023-01-10T10:01:30.200Z \x1b[36mINFO\x1b[0m[0090] Taking snapshot of full filesystem...\n"
+
+	progress := &api.BuildProgress{TotalSteps: 14}
+	assert.Nil(t, parseKanikoProgress(strings.NewReader(output), progress))
+	assert.Equal(t, "Taking snapshot of full filesystem...", progress.Step)
+	assert.Equal(t, 3, progress.StepNumber)
+	assert.Equal(t, 14, progress.TotalSteps)
+	assert.Equal(t, time.Date(2023, 1, 10, 10, 1, 30, 200000000, time.UTC), progress.LastActivityAt.UTC())
+	assert.Equal(t, 2, progress.LastActivityLines)
+
+	// the next output starts at the second of the last activity, stored without its fraction
+	lastActivityAt := metav1.NewTime(progress.LastActivityAt.Truncate(time.Second))
+	progress.LastActivityAt = &lastActivityAt
+	output = "2023-01-10T10:01:30.100Z \x1b[36mINFO\x1b[0m[0090] ENV JAVA_OPTS=-Xmx1g\n" +
+		"2023-01-10T10:01:30.200Z \x1b[36mINFO\x1b[0m[0090] Taking snapshot of full filesystem...\n" +
+		"2023-01-10T10:01:30.900Z \x1b[36mINFO\x1b[0m[0090] LABEL version=1.0\n" +
+		"2023-01-10T10:02:10.250Z INFO[0130] Pushing image to quay.io/kiegroup/buildexample:latest\n"
+	pushStartedAt := parseKanikoProgress(strings.NewReader(output), progress)
+	assert.Equal(t, "Pushing image to quay.io/kiegroup/buildexample:latest", progress.Step)
+	assert.Equal(t, 4, progress.StepNumber)
+	assert.Equal(t, 1, progress.LastActivityLines)
+	assert.Equal(t, time.Date(2023, 1, 10, 10, 2, 10, 250000000, time.UTC), pushStartedAt.UTC())
+
+	// no new output
+	assert.Nil(t, parseKanikoProgress(strings.NewReader(""), progress))
+	assert.Equal(t, 4, progress.StepNumber)
+	assert.Equal(t, 1, progress.LastActivityLines)
+}
+
+func TestScheduleWithProgress(t *testing.T) {
+	c, err := test.NewFakeClient()
+	assert.NoError(t, err)
+
+	dockerFile, err := os.ReadFile("testdata/Dockerfile")
+	assert.NoError(t, err)
+
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{
+			Namespace: "test",
+			Name:      "testPlatform",
+		},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy:   api.BuildStrategyPod,
+			PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+			Timeout:         &metav1.Duration{Duration: 5 * time.Minute},
+		},
+	}
+	scheduler, err := NewBuild(BuilderInfo{FinalImageName: "quay.io/kiegroup/buildexample:latest", BuildUniqueName: "build-progress", Platform: platform})
+	assert.NoError(t, err)
+	build, err := scheduler.WithClient(c).WithResource("Dockerfile", dockerFile).Schedule(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, &api.BuildProgress{TotalSteps: 14}, build.Status.Progress)
+}
@@ -0,0 +1,258 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/client"
+	"github.com/kiegroup/container-builder/util"
+	"github.com/kiegroup/container-builder/util/log"
+)
+
+// DefaultRebuildInterval how often the RebuildWatcher checks the base images by default
+const DefaultRebuildInterval = time.Hour
+
+// RebuildWatcher periodically resolves the pinned base images of the builds, rebuilding the builds whose base images moved,
+// for example after a security patch. See FindStaleBuilds.
+// Only the latest build of every image is checked, so that a build is not rebuilt again once its rebuild is scheduled.
+// It can be run by a controller-runtime manager, being a Runnable.
+type RebuildWatcher struct {
+	// Client the client of the cluster, reading the registry secrets of the builds
+	Client client.Client
+	L      log.Logger
+	// Builds lists the builds to check, along with the rebuilds scheduled so far
+	Builds func(ctx context.Context) ([]*api.Build, error)
+	// Rebuild schedules a new build of the stale build, usually with RebuildOf, returning it
+	Rebuild func(ctx context.Context, stale StaleBuild) (*api.Build, error)
+	// Interval how often the base images are checked
+	Interval time.Duration
+	// Concurrency how many rebuilds can run at the same time, the others are postponed. Unlimited if not set.
+	Concurrency int
+	// DryRun when true, the stale builds are only reported
+	DryRun bool
+
+	// running the rebuilds not finished yet, by namespace and name
+	running map[string]bool
+}
+
+// NewRebuildWatcher creates a RebuildWatcher of the listed builds, rebuilding them with the given function every DefaultRebuildInterval
+func NewRebuildWatcher(c client.Client, builds func(ctx context.Context) ([]*api.Build, error),
+	rebuild func(ctx context.Context, stale StaleBuild) (*api.Build, error)) *RebuildWatcher {
+	return &RebuildWatcher{
+		Client:   c,
+		L:        log.WithName(util.ComponentName).WithName("rebuild-watcher"),
+		Builds:   builds,
+		Rebuild:  rebuild,
+		Interval: DefaultRebuildInterval,
+		running:  map[string]bool{},
+	}
+}
+
+// Start checks the builds every Interval until the context is done. The errors are logged, the checks go on.
+func (w *RebuildWatcher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := w.Check(ctx); err != nil {
+			w.L.Errorf(err, "Failed to check the base images of the builds")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check checks the builds once, returning the stale ones whether they have been rebuilt or not.
+// A build is rebuilt once even if several of its base images moved.
+func (w *RebuildWatcher) Check(ctx context.Context) ([]StaleBuild, error) {
+	builds, err := w.Builds(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot list the builds")
+	}
+	running := w.countRunning(builds)
+	stale, err := FindStaleBuilds(ctx, w.Client, latestBuilds(builds))
+	if err != nil {
+		return nil, err
+	}
+
+	rebuilt := map[string]bool{}
+	for _, s := range stale {
+		key := buildKey(s.Build)
+		if rebuilt[key] {
+			continue
+		}
+		rebuilt[key] = true
+		switch {
+		case w.DryRun:
+			w.L.Infof("Build %s is stale, base image %s moved to %s", s.Build.Name, s.BaseImage, s.CurrentDigest)
+		case w.Concurrency > 0 && running >= w.Concurrency:
+			w.L.Infof("Rebuild of build %s postponed, %d rebuilds are running", s.Build.Name, running)
+		default:
+			rebuild, err := w.Rebuild(ctx, s)
+			if err != nil {
+				w.L.Errorf(err, "Failed to rebuild build %s", s.Build.Name)
+				continue
+			}
+			w.L.Infof("Build %s rebuilt by %s, base image %s moved to %s", s.Build.Name, rebuild.Name, s.BaseImage, s.CurrentDigest)
+			w.running[buildKey(rebuild)] = true
+			running++
+		}
+	}
+	return stale, nil
+}
+
+// countRunning forgets the rebuilds finished or deleted and returns how many are still running
+func (w *RebuildWatcher) countRunning(builds []*api.Build) int {
+	running := map[string]bool{}
+	for _, build := range builds {
+		if key := buildKey(build); w.running[key] && !build.Status.Phase.IsFinished() {
+			running[key] = true
+		}
+	}
+	w.running = running
+	return len(running)
+}
+
+// RebuildOf returns the Scheduler of a new build with the given name, built from the same spec and resources as the given build.
+// The base images pinned by the given build are pinned again to their current digest.
+func RebuildOf(ctx context.Context, c client.Client, build *api.Build, name string) (Scheduler, error) {
+	volume := build.Status.ResourceVolume
+	if volume == nil || volume.ReferenceType != api.ResourceReferenceTypeConfigMap {
+		return nil, errors.Errorf("the resources of build %s are unknown", build.Name)
+	}
+	configMap, err := getResourcesConfigMap(ctx, c, build.Namespace, volume.ReferenceName)
+	if err != nil {
+		return nil, err
+	}
+	if configMap == nil {
+		return nil, errors.Errorf("the resources of build %s are not found", build.Name)
+	}
+
+	// the images are pinned again while scheduling the rebuild
+	unpin := func(content string) string {
+		for _, image := range build.Status.PinnedBaseImages {
+			content = strings.ReplaceAll(content, image, unpinImage(image))
+		}
+		return content
+	}
+	rebuild := &api.Build{Spec: *build.Spec.DeepCopy()}
+	rebuild.Name = name
+	rebuild.Namespace = build.Namespace
+	task := kanikoTask(rebuild)
+	if task == nil {
+		return nil, errors.Errorf("build %s has no Kaniko task", build.Name)
+	}
+	task.BaseImage = unpin(task.BaseImage)
+	task.ContextDir = path.Join("/builder", name, "context")
+
+	scheduler := newKanikoScheduler(BuildContext{Build: rebuild, BaseImage: task.BaseImage}, task)
+	scheduler.WithClient(c)
+	targets := make([]string, 0, len(configMap.Data)+len(configMap.BinaryData))
+	for target := range configMap.Data {
+		targets = append(targets, target)
+	}
+	for target := range configMap.BinaryData {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	for _, target := range targets {
+		if content, ok := configMap.Data[target]; ok {
+			if target == dockerfileName {
+				content = unpin(content)
+			}
+			scheduler.WithResource(target, []byte(content))
+		} else {
+			scheduler.WithResource(target, configMap.BinaryData[target])
+		}
+	}
+	return scheduler, nil
+}
+
+// unpinImage returns the tag of an image pinned with its tag and digest, like "busybox:latest".
+// The images pinned without tag are returned as they are.
+func unpinImage(image string) string {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return image
+	}
+	tagged, isTagged := named.(reference.Tagged)
+	if _, isDigested := named.(reference.Digested); !isTagged || !isDigested {
+		return image
+	}
+	ref, err := reference.WithTag(reference.TrimNamed(named), tagged.Tag())
+	if err != nil {
+		return image
+	}
+	return reference.FamiliarString(ref)
+}
+
+// latestBuilds returns the latest build of every image, the builds not created yet being the latest ones
+func latestBuilds(builds []*api.Build) []*api.Build {
+	latest := map[string]*api.Build{}
+	var images []string
+	for _, build := range builds {
+		task := kanikoTask(build)
+		if task == nil {
+			continue
+		}
+		image := build.Namespace + "/" + publishedImage(task)
+		previous, ok := latest[image]
+		if !ok {
+			images = append(images, image)
+		}
+		if !ok || isBuiltBefore(previous, build) {
+			latest[image] = build
+		}
+	}
+	result := make([]*api.Build, 0, len(images))
+	for _, image := range images {
+		result = append(result, latest[image])
+	}
+	return result
+}
+
+// isBuiltBefore tells whether the first build has been created before the second one
+func isBuiltBefore(first, second *api.Build) bool {
+	firstTime, secondTime := buildCreationTime(first), buildCreationTime(second)
+	if firstTime == nil || secondTime == nil {
+		return firstTime != nil
+	}
+	return firstTime.Before(secondTime)
+}
+
+func buildCreationTime(build *api.Build) *metav1.Time {
+	if timings := build.Status.Timings; timings != nil && timings.CreatedAt != nil {
+		return timings.CreatedAt
+	}
+	return build.Status.StartedAt
+}
+
+func buildKey(build *api.Build) string {
+	return build.Namespace + "/" + build.Name
+}
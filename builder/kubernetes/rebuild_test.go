@@ -0,0 +1,126 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/util/test"
+)
+
+func TestRebuildWatcher(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+	pushTestImage(t, server.URL, "base", "1", nil)
+
+	c, err := test.NewFakeClient()
+	assert.NoError(t, err)
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{
+			Namespace: "test",
+			Name:      "testPlatform",
+		},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy:   api.BuildStrategyPod,
+			PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+			Registry:        api.RegistrySpec{Address: host, Insecure: true},
+			Timeout:         &metav1.Duration{Duration: 5 * time.Minute},
+			PinBaseImages:   true,
+		},
+	}
+	var builds []*api.Build
+	for _, image := range []string{"app", "other"} {
+		scheduler, err := NewBuild(BuilderInfo{FinalImageName: image + ":latest", BuildUniqueName: image, Platform: platform})
+		assert.NoError(t, err)
+		build, err := scheduler.WithClient(c).
+			WithResource("Dockerfile", []byte("FROM "+host+"/base:1\nCOPY greetings.sw.json .\n")).
+			WithResource("greetings.sw.json", []byte("{}")).
+			Schedule(context.TODO())
+		assert.NoError(t, err)
+		build.Status.Phase = api.BuildPhaseSucceeded
+		builds = append(builds, build)
+	}
+
+	watcher := NewRebuildWatcher(c,
+		func(ctx context.Context) ([]*api.Build, error) {
+			return builds, nil
+		},
+		func(ctx context.Context, stale StaleBuild) (*api.Build, error) {
+			scheduler, err := RebuildOf(ctx, c, stale.Build, stale.Build.Name+"-r1")
+			if err != nil {
+				return nil, err
+			}
+			build, err := scheduler.Schedule(ctx)
+			if err == nil {
+				builds = append(builds, build)
+			}
+			return build, err
+		})
+	watcher.Concurrency = 1
+	stale, err := watcher.Check(context.TODO())
+	assert.NoError(t, err)
+	assert.Empty(t, stale)
+
+	// the stale builds are only reported in dry run
+	movedDigest := pushTestImage(t, server.URL, "base", "1", map[string]string{"version": "2"})
+	watcher.DryRun = true
+	stale, err = watcher.Check(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, stale, 2)
+	assert.Len(t, builds, 2)
+
+	// a single rebuild runs at a time
+	watcher.DryRun = false
+	stale, err = watcher.Check(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, stale, 2)
+	assert.Len(t, builds, 3)
+	rebuild := builds[2]
+	assert.Equal(t, "app-r1", rebuild.Name)
+	assert.Equal(t, api.BuildPhaseScheduling, rebuild.Status.Phase)
+	assert.Equal(t, host+"/base:1@"+movedDigest.String(), rebuild.Status.BaseImage)
+	configMap := &corev1.ConfigMap{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: "test", Name: rebuild.Status.ResourceVolume.ReferenceName}, configMap))
+	assert.Equal(t, "FROM "+host+"/base:1@"+movedDigest.String()+"\nCOPY greetings.sw.json .\n", configMap.Data["Dockerfile"])
+	assert.Equal(t, "{}", configMap.Data["greetings.sw.json"])
+
+	// the rebuild is the latest build of the image, so it isn't rebuilt again
+	stale, err = watcher.Check(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, stale, 1)
+	assert.Equal(t, "other", stale[0].Build.Name)
+	assert.Len(t, builds, 3)
+
+	rebuild.Status.Phase = api.BuildPhaseSucceeded
+	stale, err = watcher.Check(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, stale, 1)
+	assert.Len(t, builds, 4)
+	assert.Equal(t, "other-r1", builds[3].Name)
+}
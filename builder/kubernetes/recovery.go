@@ -40,7 +40,7 @@ func newErrorRecoveryAction() Action {
 }
 
 type errorRecoveryAction struct {
-	baseAction
+	BaseAction
 	backOff backoff.Backoff
 }
 
@@ -0,0 +1,235 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/client"
+	"github.com/kiegroup/container-builder/util/log"
+)
+
+// buildArgFlag the Kaniko flag of the build arguments
+const buildArgFlag = "--build-arg"
+
+// buildRedactor returns the Redactor of the build secrets, masking the values of the secret keys set by log.SetSecretKeyPatterns
+// and of the build arguments read from Secrets. The values of the secret build arguments are masked wherever they appear,
+// like in the builder output expanding them.
+func buildRedactor(build *api.Build) *log.Redactor {
+	task := kanikoTask(build)
+	if task == nil {
+		return log.DefaultRedactor()
+	}
+	var keys []string
+	for _, arg := range task.BuildArgs {
+		if arg.ValueFrom != nil && arg.ValueFrom.SecretKeyRef != nil {
+			keys = append(keys, arg.Name)
+		}
+	}
+	redactor := log.DefaultRedactor().WithKeys(keys...)
+	return redactor.WithValues(secretBuildArgValues(redactor, task)...)
+}
+
+// secretBuildArgValues returns the literal values of the secret build arguments, of the additional flags and of the build arguments.
+// The longest values come first, so that the values containing the other ones are masked as a whole.
+func secretBuildArgValues(r *log.Redactor, task *api.KanikoTask) []string {
+	_, secretArgs := splitSecretBuildArgs(r, task.AdditionalFlags)
+	values := make([]string, 0, len(secretArgs))
+	for _, value := range secretArgs {
+		values = append(values, value)
+	}
+	for _, arg := range task.BuildArgs {
+		if arg.ValueFrom == nil && r.IsSecretKey(arg.Name) {
+			values = append(values, arg.Value)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return len(values[i]) > len(values[j])
+	})
+	return values
+}
+
+// redactStatus masks the secret values of the status messages, before they're stored or sent to events
+func redactStatus(r *log.Redactor, build *api.Build) {
+	build.Status.Error = r.Redact(build.Status.Error)
+	if build.Status.Failure != nil {
+		build.Status.Failure.Reason = r.Redact(build.Status.Failure.Reason)
+	}
+	if build.Status.Progress != nil {
+		// the step is read from the builder output, like a RUN instruction expanding the build arguments
+		build.Status.Progress.Step = r.Redact(build.Status.Progress.Step)
+	}
+	for i := range build.Status.Conditions {
+		build.Status.Conditions[i].Reason = r.Redact(build.Status.Conditions[i].Reason)
+		build.Status.Conditions[i].Message = r.Redact(build.Status.Conditions[i].Message)
+	}
+}
+
+// RedactBuild returns a copy of the build with its secret values masked, like the values of the secret build arguments,
+// so that it can be printed or stored out of a Secret
+func RedactBuild(build *api.Build) *api.Build {
+	redactor := buildRedactor(build)
+	redacted := build.DeepCopy()
+	for _, task := range redacted.Spec.Tasks {
+		if task.Kaniko == nil {
+			continue
+		}
+		for i, flag := range task.Kaniko.AdditionalFlags {
+			task.Kaniko.AdditionalFlags[i] = redactor.Redact(flag)
+		}
+		for i, arg := range task.Kaniko.BuildArgs {
+			if arg.ValueFrom == nil && redactor.IsSecretKey(arg.Name) {
+				task.Kaniko.BuildArgs[i].Value = log.Redacted
+			}
+		}
+	}
+	redactStatus(redactor, redacted)
+	return redacted
+}
+
+// splitSecretBuildArgs returns the additional flags without the values of the secret build arguments, returned by name.
+// Both the "--build-arg=KEY=VALUE" and the "--build-arg KEY=VALUE" forms are supported.
+func splitSecretBuildArgs(r *log.Redactor, flags []string) ([]string, map[string]string) {
+	result := make([]string, 0, len(flags))
+	values := map[string]string{}
+	for i := 0; i < len(flags); i++ {
+		arg, ok := strings.TrimPrefix(flags[i], buildArgFlag+"="), strings.HasPrefix(flags[i], buildArgFlag+"=")
+		if !ok && flags[i] == buildArgFlag && i+1 < len(flags) {
+			i++
+			arg, ok = flags[i], true
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		if !ok || !hasValue || !r.IsSecretKey(name) {
+			if ok {
+				result = append(result, buildArgFlag+"="+arg)
+			} else {
+				result = append(result, flags[i])
+			}
+			continue
+		}
+		// Kaniko reads the value from the environment
+		result = append(result, buildArgFlag+"="+name)
+		values[name] = value
+	}
+	return result, values
+}
+
+// validateSecretBuildArgs returns an error for every secret build argument of the additional flags and of the literal
+// build arguments which would override the builder environment, since their values are passed through it
+func validateSecretBuildArgs(build *api.Build) field.ErrorList {
+	var errs field.ErrorList
+	redactor := buildRedactor(build)
+	for i, task := range build.Spec.Tasks {
+		if task.Kaniko == nil {
+			continue
+		}
+		for j, arg := range task.Kaniko.BuildArgs {
+			if arg.ValueFrom == nil && redactor.IsSecretKey(arg.Name) && api.IsReservedEnvName(arg.Name) {
+				errs = append(errs, field.Invalid(field.NewPath("spec", "tasks").Index(i).Child("kaniko", "buildArgs").Index(j).Child("name"), arg.Name,
+					"is reserved by the builder environment, which passes the values of the secret build arguments"))
+			}
+		}
+		_, values := splitSecretBuildArgs(redactor, task.Kaniko.AdditionalFlags)
+		names := make([]string, 0, len(values))
+		for name := range values {
+			if api.IsReservedEnvName(name) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "tasks").Index(i).Child("kaniko", "additionalFlags"), buildArgFlag+"="+name,
+				"is reserved by the builder environment, which passes the values of the secret build arguments"))
+		}
+	}
+	return errs
+}
+
+// addSecretBuildArgs keeps the values of the secret build arguments in a Secret owned like the other objects of the build,
+// referenced by the container environment, so that they're never written in the builder Pod.
+// The Secret is deleted along with the builder Pod, or once the build is finished, see deleteBuildArgsSecret.
+func addSecretBuildArgs(ctx context.Context, c client.Client, build *api.Build, values map[string]string, env *[]corev1.EnvVar) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: build.Namespace,
+			Name:      buildArgsSecretName(build),
+		},
+		Data: map[string][]byte{},
+	}
+	for name, value := range values {
+		secret.Data[name] = []byte(value)
+	}
+	if err := c.Create(ctx, secret); err != nil {
+		if !k8serrors.IsAlreadyExists(err) {
+			return err
+		}
+		existing := &corev1.Secret{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, existing); err != nil {
+			return err
+		}
+		existing.Data = secret.Data
+		if err := c.Update(ctx, existing); err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		*env = append(*env, corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secret.Name},
+					Key:                  name,
+				},
+			},
+		})
+	}
+	return nil
+}
+
+// deleteBuildArgsSecret deletes the Secret of the secret build arguments, if any. Their values are only read by the builder
+// container when it starts, so the Secret is no longer needed once the builder Pod is deleted or the build is finished.
+func deleteBuildArgsSecret(ctx context.Context, c client.Client, build *api.Build) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: build.Namespace,
+			Name:      buildArgsSecretName(build),
+		},
+	}
+	if err := c.Delete(ctx, secret); err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func buildArgsSecretName(build *api.Build) string {
+	return buildPodName(build) + "-args"
+}
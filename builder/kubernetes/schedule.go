@@ -27,7 +27,7 @@ func newScheduleAction() Action {
 }
 
 type scheduleAction struct {
-	baseAction
+	BaseAction
 }
 
 // Name returns a common name of the action.
@@ -45,6 +45,23 @@ func (action *scheduleAction) Handle(ctx context.Context, build *api.Build) (*ap
 	// TODO do any work required between initialization and scheduling, like enqueueing builds
 	now := metav1.Now()
 	build.Status.StartedAt = &now
+	// every attempt is timed from scratch, only the build creation is kept
+	build.Status.Timings = &api.BuildTimings{
+		CreatedAt: buildTimings(build).CreatedAt,
+		QueuedAt:  &now,
+	}
+	if image := action.findInputHashImage(ctx, build); image != nil {
+		action.L.Infof("Build %s reuses the image %s built from the same inputs", build.Name, image.Digest)
+		build.Status.Phase = api.BuildPhaseSucceeded
+		build.Status.Image = kanikoTask(build).Image
+		build.Status.Digest = image.Digest.String()
+		build.Status.Duration = "0s"
+		return build, nil
+	}
+	// the progress of a new attempt starts from scratch
+	if build.Status.Progress != nil {
+		build.Status.Progress = &api.BuildProgress{TotalSteps: build.Status.Progress.TotalSteps}
+	}
 	build.Status.Phase = api.BuildPhasePending
 
 	return build, nil
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"sort"
+	"sync"
+)
+
+const (
+	// KanikoSchedulerHandlerName name of the built-in Kaniko SchedulerHandler
+	KanikoSchedulerHandlerName = "kaniko"
+	// DefaultSchedulerHandlerPriority priority of the built-in SchedulerHandlers.
+	// Register a handler with a higher priority to take over the builds they handle.
+	DefaultSchedulerHandlerPriority = 0
+)
+
+type registeredSchedulerHandler struct {
+	name     string
+	priority int
+	handler  SchedulerHandler
+}
+
+var (
+	schedulersMutex sync.RWMutex
+	// schedulers registered handlers, sorted by priority
+	schedulers []registeredSchedulerHandler
+)
+
+func init() {
+	RegisterSchedulerHandler(KanikoSchedulerHandlerName, DefaultSchedulerHandlerPriority, &kanikoSchedulerHandler{})
+}
+
+// RegisterSchedulerHandler registers a SchedulerHandler under the given name, replacing any handler already registered with the same name.
+// NewBuild asks the handlers whether they can handle a build by descending priority, handlers with the same priority are sorted by name.
+// It's safe to call it concurrently, usually from the init function of the package providing the handler.
+func RegisterSchedulerHandler(name string, priority int, handler SchedulerHandler) {
+	schedulersMutex.Lock()
+	defer schedulersMutex.Unlock()
+	unregisterSchedulerHandler(name)
+	schedulers = append(schedulers, registeredSchedulerHandler{name: name, priority: priority, handler: handler})
+	sort.SliceStable(schedulers, func(i, j int) bool {
+		if schedulers[i].priority != schedulers[j].priority {
+			return schedulers[i].priority > schedulers[j].priority
+		}
+		return schedulers[i].name < schedulers[j].name
+	})
+}
+
+// UnregisterSchedulerHandler removes the SchedulerHandler registered under the given name, if any
+func UnregisterSchedulerHandler(name string) {
+	schedulersMutex.Lock()
+	defer schedulersMutex.Unlock()
+	unregisterSchedulerHandler(name)
+}
+
+// SchedulerHandlerNames returns the names of the registered SchedulerHandlers, in the order they're asked to handle a build
+func SchedulerHandlerNames() []string {
+	schedulersMutex.RLock()
+	defer schedulersMutex.RUnlock()
+	names := make([]string, 0, len(schedulers))
+	for _, s := range schedulers {
+		names = append(names, s.name)
+	}
+	return names
+}
+
+func unregisterSchedulerHandler(name string) {
+	for i, s := range schedulers {
+		if s.name == name {
+			schedulers = append(schedulers[:i], schedulers[i+1:]...)
+			return
+		}
+	}
+}
+
+// findSchedulerHandler returns the handler with the highest priority able to handle the build, nil if none
+func findSchedulerHandler(info BuilderInfo) SchedulerHandler {
+	schedulersMutex.RLock()
+	defer schedulersMutex.RUnlock()
+	for _, s := range schedulers {
+		if s.handler.CanHandle(info) {
+			return s.handler
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiegroup/container-builder/api"
+)
+
+type testSchedulerHandler struct {
+	kanikoSchedulerHandler
+	created []string
+}
+
+func (h *testSchedulerHandler) CreateScheduler(info BuilderInfo, buildCtx BuildContext) Scheduler {
+	h.created = append(h.created, info.BuildUniqueName)
+	return h.kanikoSchedulerHandler.CreateScheduler(info, buildCtx)
+}
+
+func (h *testSchedulerHandler) CanHandle(info BuilderInfo) bool {
+	return info.Platform.Spec.IsOptionEnabled("InHouseBuilder")
+}
+
+func TestRegisterSchedulerHandler(t *testing.T) {
+	high := &testSchedulerHandler{}
+	low := &testSchedulerHandler{}
+	RegisterSchedulerHandler("in-house-low", DefaultSchedulerHandlerPriority-1, low)
+	RegisterSchedulerHandler("in-house", DefaultSchedulerHandlerPriority+1, high)
+	RegisterSchedulerHandler("in-house-same", DefaultSchedulerHandlerPriority+1, high)
+	defer UnregisterSchedulerHandler("in-house-low")
+	defer UnregisterSchedulerHandler("in-house")
+	defer UnregisterSchedulerHandler("in-house-same")
+
+	assert.Equal(t, []string{"in-house", "in-house-same", KanikoSchedulerHandlerName, "in-house-low"}, SchedulerHandlerNames())
+
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{Namespace: "test", Name: "testPlatform"},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy:   api.BuildStrategyPod,
+			PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+		},
+	}
+	_, err := NewBuild(BuilderInfo{FinalImageName: "quay.io/kiegroup/buildexample:latest", BuildUniqueName: "kaniko", Platform: platform})
+	assert.NoError(t, err)
+	assert.Empty(t, high.created)
+
+	platform.Spec.PublishStrategyOptions = map[string]string{"InHouseBuilder": "true"}
+	_, err = NewBuild(BuilderInfo{FinalImageName: "quay.io/kiegroup/buildexample:latest", BuildUniqueName: "in-house", Platform: platform})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"in-house"}, high.created)
+	assert.Empty(t, low.created)
+
+	// registering a name again replaces the handler
+	RegisterSchedulerHandler("in-house", DefaultSchedulerHandlerPriority-2, high)
+	assert.Equal(t, []string{"in-house-same", KanikoSchedulerHandlerName, "in-house-low", "in-house"}, SchedulerHandlerNames())
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiegroup/container-builder/api"
+)
+
+// kanikoPushMessage the message logged by Kaniko when it starts to push the image
+const kanikoPushMessage = "Pushing image to "
+
+// setPodTimings records when the builder Pod was scheduled and when its builder container started
+func setPodTimings(build *api.Build, pod *corev1.Pod) {
+	timings := buildTimings(build)
+	if timings.ScheduledAt == nil {
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionTrue {
+				scheduledAt := condition.LastTransitionTime
+				timings.ScheduledAt = &scheduledAt
+			}
+		}
+	}
+	if timings.ContainerStartedAt == nil {
+		if status := builderContainerStatus(build, pod); status != nil {
+			switch {
+			case status.State.Running != nil:
+				startedAt := status.State.Running.StartedAt
+				timings.ContainerStartedAt = &startedAt
+			case status.State.Terminated != nil:
+				startedAt := status.State.Terminated.StartedAt
+				timings.ContainerStartedAt = &startedAt
+			}
+		}
+	}
+	timings.SetDurations()
+}
+
+// setFinishedTimings records when the builder finished. The time it started to push the image is told by its output, see setProgress.
+func setFinishedTimings(build *api.Build, pod *corev1.Pod, finishedAt metav1.Time) {
+	timings := buildTimings(build)
+	if pod != nil {
+		setPodTimings(build, pod)
+	}
+	if !finishedAt.IsZero() {
+		timings.FinishedAt = &finishedAt
+	}
+	timings.SetDurations()
+}
+
+// buildTimings returns the timings of the build, creating them if needed
+func buildTimings(build *api.Build) *api.BuildTimings {
+	if build.Status.Timings == nil {
+		build.Status.Timings = &api.BuildTimings{}
+	}
+	return build.Status.Timings
+}
+
+// builderContainerName returns the name of the container running the Kaniko task, if any
+func builderContainerName(build *api.Build) string {
+	if task := kanikoTask(build); task != nil {
+		return strings.ToLower(task.Name)
+	}
+	return ""
+}
+
+// builderContainerStatus returns the status of the container running the Kaniko task, or of the first container
+func builderContainerStatus(build *api.Build, pod *corev1.Pod) *corev1.ContainerStatus {
+	name := builderContainerName(build)
+	for i := range pod.Status.ContainerStatuses {
+		if pod.Status.ContainerStatuses[i].Name == name {
+			return &pod.Status.ContainerStatuses[i]
+		}
+	}
+	if len(pod.Status.ContainerStatuses) > 0 {
+		return &pod.Status.ContainerStatuses[0]
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/util/test"
+)
+
+func TestBuildTimings(t *testing.T) {
+	ns := "test"
+	queuedAt := metav1.NewTime(time.Now().Add(-10 * time.Minute).Truncate(time.Second))
+	scheduledAt := metav1.NewTime(queuedAt.Add(time.Minute))
+	startedAt := metav1.NewTime(scheduledAt.Add(2 * time.Minute))
+	finishedAt := metav1.NewTime(startedAt.Add(3 * time.Minute))
+	build := &api.Build{
+		ObjectReference: api.ObjectReference{Namespace: ns, Name: "build1"},
+		Spec: api.BuildSpec{
+			Strategy: api.BuildStrategyPod,
+			Timeout:  metav1.Duration{Duration: time.Hour},
+			Tasks: []api.Task{{Kaniko: &api.KanikoTask{
+				BaseTask:    api.BaseTask{Name: "KanikoTask"},
+				PublishTask: api.PublishTask{Image: "quay.io/kiegroup/buildexample:latest"},
+			}}},
+		},
+		Status: api.BuildStatus{
+			Phase:     api.BuildPhaseRunning,
+			StartedAt: &queuedAt,
+			Timings:   &api.BuildTimings{QueuedAt: &queuedAt},
+		},
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: buildPodName(build), Namespace: ns},
+		Status: v1.PodStatus{
+			Phase: v1.PodSucceeded,
+			Conditions: []v1.PodCondition{
+				{Type: v1.PodScheduled, Status: v1.ConditionTrue, LastTransitionTime: scheduledAt},
+			},
+			ContainerStatuses: []v1.ContainerStatus{{
+				Name: "kanikotask",
+				State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{
+					StartedAt:  startedAt,
+					FinishedAt: finishedAt,
+				}},
+			}},
+		},
+	}
+	c, err := test.NewFakeClient(pod)
+	assert.NoError(t, err)
+
+	build, err = FromBuild(build).WithClient(c).Reconcile(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, api.BuildPhaseSucceeded, build.Status.Phase)
+
+	timings := build.Status.Timings
+	assert.Equal(t, &scheduledAt, timings.ScheduledAt)
+	assert.Equal(t, &startedAt, timings.ContainerStartedAt)
+	assert.Equal(t, &finishedAt, timings.FinishedAt)
+	assert.Equal(t, time.Minute, timings.QueueDuration.Duration)
+	assert.Equal(t, 2*time.Minute, timings.PullDuration.Duration)
+	// the fake logs don't tell when the push started
+	assert.Nil(t, timings.PushStartedAt)
+	assert.Equal(t, 3*time.Minute, timings.BuildDuration.Duration)
+	assert.Nil(t, timings.PushDuration)
+
+	pushStartedAt := metav1.NewTime(startedAt.Add(2 * time.Minute))
+	timings.PushStartedAt = &pushStartedAt
+	timings.SetDurations()
+	assert.Equal(t, 2*time.Minute, timings.BuildDuration.Duration)
+	assert.Equal(t, time.Minute, timings.PushDuration.Duration)
+}
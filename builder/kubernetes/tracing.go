@@ -0,0 +1,140 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/util"
+)
+
+const (
+	// tracerName the instrumentation name of the builds spans
+	tracerName = "github.com/kiegroup/container-builder/builder/kubernetes"
+	// traceParentEnv the environment variable holding the W3C trace context in the builder containers
+	traceParentEnv = "TRACEPARENT"
+	// traceStateEnv the environment variable holding the W3C trace state in the builder containers
+	traceStateEnv = "TRACESTATE"
+)
+
+// tracePropagator propagates the trace context into the builder Pod, as W3C traceparent and tracestate
+var tracePropagator = propagation.TraceContext{}
+
+// NewTracerProvider returns a TracerProvider exporting the spans of the builds with the given exporter, like an OTLP one.
+// The spans are exported in batches, unless sync is true, for example with the tracetest.InMemoryExporter used in the tests.
+func NewTracerProvider(exporter sdktrace.SpanExporter, sync bool) *sdktrace.TracerProvider {
+	exporterOption := sdktrace.WithBatcher(exporter)
+	if sync {
+		exporterOption = sdktrace.WithSyncer(exporter)
+	}
+	return sdktrace.NewTracerProvider(
+		exporterOption,
+		sdktrace.WithResource(sdkresource.NewSchemaless(semconv.ServiceNameKey.String(util.ComponentName))),
+	)
+}
+
+// tracer returns the tracer of the build, from the TracerProvider set by the caller or the global one, which records nothing by default
+func (c *BuildContext) tracer() trace.Tracer {
+	provider := c.TracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return provider.Tracer(tracerName)
+}
+
+// startActionSpan starts the span of the action invocation, child of the first span of the build if its trace is known
+func (b *builder) startActionSpan(a Action, build *api.Build) (context.Context, trace.Span) {
+	return b.Context.tracer().Start(buildTraceContext(b.Context.C, build), a.Name(),
+		trace.WithAttributes(
+			attribute.String("build.name", build.Name),
+			attribute.String("build.namespace", build.Namespace),
+			attribute.String("build.phase", phaseName(build.Status.Phase)),
+		))
+}
+
+// endActionSpan ends the span of the action invocation, recording the trace of the build in the given status if it's the first span
+func endActionSpan(span trace.Span, build *api.Build, err error) {
+	defer span.End()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	if build == nil {
+		return
+	}
+	span.SetAttributes(attribute.String("build.phase.result", phaseName(build.Status.Phase)))
+	if sc := span.SpanContext(); sc.IsValid() && len(build.Status.TraceID) == 0 {
+		build.Status.TraceID = sc.TraceID().String()
+		build.Status.SpanID = sc.SpanID().String()
+	}
+}
+
+// buildTraceContext returns a context holding the first span of the build as remote parent, so that all the spans of the build share its trace
+func buildTraceContext(ctx context.Context, build *api.Build) context.Context {
+	traceID, err := trace.TraceIDFromHex(build.Status.TraceID)
+	if err != nil {
+		return ctx
+	}
+	spanID, err := trace.SpanIDFromHex(build.Status.SpanID)
+	if err != nil {
+		return ctx
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	}))
+}
+
+// injectTraceContext propagates the trace context into the builder Pod annotations and containers environment,
+// so that the tools running in the Pod can link their own spans to the build
+func injectTraceContext(ctx context.Context, pod *corev1.Pod) {
+	carrier := propagation.MapCarrier{}
+	tracePropagator.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	var env []corev1.EnvVar
+	for key, value := range carrier {
+		pod.Annotations[key] = value
+	}
+	if traceParent := carrier.Get("traceparent"); len(traceParent) > 0 {
+		env = append(env, corev1.EnvVar{Name: traceParentEnv, Value: traceParent})
+	}
+	if traceState := carrier.Get("tracestate"); len(traceState) > 0 {
+		env = append(env, corev1.EnvVar{Name: traceStateEnv, Value: traceState})
+	}
+	for i := range pod.Spec.Containers {
+		pod.Spec.Containers[i].Env = append(pod.Spec.Containers[i].Env, env...)
+	}
+}
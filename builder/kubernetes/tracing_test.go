@@ -0,0 +1,105 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/util/test"
+)
+
+func TestBuildTracing(t *testing.T) {
+	ns := "test"
+	c, err := test.NewFakeClient()
+	assert.NoError(t, err)
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewTracerProvider(exporter, true)
+
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{
+			Namespace: ns,
+			Name:      "testPlatform",
+		},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy:   api.BuildStrategyPod,
+			PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+			Timeout:         &metav1.Duration{Duration: 5 * time.Minute},
+		},
+	}
+	scheduler, err := NewBuild(BuilderInfo{FinalImageName: "quay.io/kiegroup/buildexample:latest", BuildUniqueName: "build-traced", Platform: platform})
+	assert.NoError(t, err)
+	build, err := scheduler.WithClient(c).WithTracerProvider(provider).Schedule(context.TODO())
+	assert.NoError(t, err)
+	assert.NotEmpty(t, build.Status.TraceID)
+	assert.NotEmpty(t, build.Status.SpanID)
+
+	// the schedule action moves the build to Pending, then the builder Pod is created
+	for i := 0; i < 2; i++ {
+		build, err = FromBuild(build).WithClient(c).WithTracerProvider(provider).Reconcile(context.TODO())
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, api.BuildPhasePending, build.Status.Phase)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 3)
+	for i, name := range []string{"initialize-pod", "schedule", "monitor-pod"} {
+		assert.Equal(t, name, spans[i].Name)
+		assert.Equal(t, build.Status.TraceID, spans[i].SpanContext.TraceID().String())
+	}
+	assert.Equal(t, build.Status.SpanID, spans[0].SpanContext.SpanID().String())
+	assert.Equal(t, build.Status.SpanID, spans[1].Parent.SpanID().String())
+	assert.Equal(t, build.Status.SpanID, spans[2].Parent.SpanID().String())
+
+	// the trace context of the action creating the Pod is propagated to the builder
+	pod := &v1.Pod{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: ns, Name: buildPodName(build)}, pod))
+	traceParent := "00-" + build.Status.TraceID + "-" + spans[2].SpanContext.SpanID().String() + "-01"
+	assert.Equal(t, traceParent, pod.Annotations["traceparent"])
+	assert.Contains(t, pod.Spec.Containers[0].Env, v1.EnvVar{Name: traceParentEnv, Value: traceParent})
+}
+
+func TestBuildWithoutTracing(t *testing.T) {
+	c, err := test.NewFakeClient()
+	assert.NoError(t, err)
+
+	platform := api.PlatformBuild{
+		ObjectReference: api.ObjectReference{
+			Namespace: "test",
+			Name:      "testPlatform",
+		},
+		Spec: api.PlatformBuildSpec{
+			BuildStrategy:   api.BuildStrategyPod,
+			PublishStrategy: api.PlatformBuildPublishStrategyKaniko,
+		},
+	}
+	scheduler, err := NewBuild(BuilderInfo{FinalImageName: "quay.io/kiegroup/buildexample:latest", BuildUniqueName: "build-untraced", Platform: platform})
+	assert.NoError(t, err)
+	build, err := scheduler.WithClient(c).Schedule(context.TODO())
+	assert.NoError(t, err)
+	assert.Empty(t, build.Status.TraceID)
+	assert.Empty(t, build.Status.SpanID)
+}
@@ -0,0 +1,166 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// buildContextLabel label added to every builder Pod, holding the Build name
+const buildContextLabel = "kie.kogito.org/buildContext"
+
+// watchResyncPeriod how often a watched Build is reconciled when no builder Pod events are received.
+// Some phases, like the error recovery back off, don't depend on the builder Pod.
+var watchResyncPeriod = 10 * time.Second
+
+// watchMaxFailures how many consecutive reconcile failures stop the watch of a Build
+var watchMaxFailures = 5
+
+// BuildCallback is called with the updated Build every time a watched Build is reconciled.
+// When the reconciliation fails, it's called with the last known Build and the error.
+type BuildCallback func(build *api.Build, err error)
+
+// Watch reconciles the Build every time its builder Pod changes, until the Build is finished, the given context is done or
+// the reconciliation keeps failing. Every reconciled Build is sent to the returned channel and passed to the given callback,
+// if any, which gets the reconcile errors too. The channel is closed once the watch stops.
+func (b *builder) Watch(ctx context.Context, callback BuildCallback) (<-chan *api.Build, error) {
+	updates := make(chan *api.Build)
+	if _, err := b.watch(ctx, func(build *api.Build, err error) {
+		if callback != nil {
+			callback(build, err)
+		}
+		if err != nil {
+			return
+		}
+		select {
+		case updates <- build:
+		case <-ctx.Done():
+		}
+	}, func() {
+		close(updates)
+	}); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// WaitForCompletion watches the Build until it's finished, returning its last state.
+// An error is returned if the context is done or the reconciliation keeps failing before that.
+func (b *builder) WaitForCompletion(ctx context.Context) (*api.Build, error) {
+	if b.Context.Build.Status.Phase.IsFinished() {
+		return b.Context.Build, nil
+	}
+	done := make(chan struct{})
+	lastErr, err := b.watch(ctx, nil, func() {
+		close(done)
+	})
+	if err != nil {
+		return nil, err
+	}
+	<-done
+	if !b.Context.Build.Status.Phase.IsFinished() {
+		if *lastErr != nil {
+			return b.Context.Build, errors.Wrapf(*lastErr, "build %s not finished", b.Context.Build.Name)
+		}
+		return b.Context.Build, errors.Wrapf(ctx.Err(), "build %s not finished", b.Context.Build.Name)
+	}
+	return b.Context.Build, nil
+}
+
+// watch starts a Pod informer filtered by the build context label, reconciling the build upon every event.
+// The watch stops after watchMaxFailures consecutive reconcile failures, the returned error pointer holding the last reconcile
+// error once the watch stops.
+func (b *builder) watch(ctx context.Context, onUpdate BuildCallback, onStop func()) (*error, error) {
+	if b.Context.Client == nil {
+		return nil, errors.New("a client is required to watch builds")
+	}
+	build := b.Context.Build
+	// the informer resync is not needed, the builds are reconciled periodically anyway
+	factory := informers.NewSharedInformerFactoryWithOptions(b.Context.Client, 0,
+		informers.WithNamespace(build.Namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = buildContextLabel + "=" + build.Name
+		}))
+	informer := factory.Core().V1().Pods().Informer()
+
+	events := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case events <- struct{}{}:
+		default:
+			// a reconciliation is already pending
+		}
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { notify() },
+		UpdateFunc: func(oldObj, newObj interface{}) { notify() },
+		DeleteFunc: func(obj interface{}) { notify() },
+	})
+
+	stop := make(chan struct{})
+	factory.Start(stop)
+
+	var lastErr error
+	failures := 0
+	go func() {
+		defer onStop()
+		defer close(stop)
+
+		ticker := time.NewTicker(watchResyncPeriod)
+		defer ticker.Stop()
+		for {
+			target, err := b.Reconcile(ctx)
+			lastErr = err
+			if err != nil {
+				failures++
+				b.L.Errorf(err, "Failed to reconcile build %s", build.Name)
+				if onUpdate != nil {
+					onUpdate(b.Context.Build, err)
+				}
+				if failures >= watchMaxFailures {
+					b.L.Warnf("Stopped watching build %s after %d consecutive reconcile failures", build.Name, failures)
+					return
+				}
+			} else {
+				failures = 0
+				b.Context.Build = target
+				if onUpdate != nil {
+					onUpdate(target, nil)
+				}
+				if target.Status.Phase.IsFinished() {
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-events:
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return &lastErr, nil
+}
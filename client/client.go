@@ -20,8 +20,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/kiegroup/container-builder/util"
+	"github.com/kiegroup/container-builder/util/openshift"
 	user "github.com/mitchellh/go-homedir"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -53,6 +55,8 @@ type Client interface {
 	GetScheme() *runtime.Scheme
 	GetConfig() *rest.Config
 	GetCurrentNamespace(kubeConfig string) (string, error)
+	// IsOpenShift returns true if the client is connected to an OpenShift cluster, discovered once per client unless it fails.
+	IsOpenShift() (bool, error)
 }
 
 // Injectable identifies objects that can receive a Client.
@@ -70,6 +74,9 @@ type defaultClient struct {
 	kubernetes.Interface
 	scheme *runtime.Scheme
 	config *rest.Config
+	// openShift the cached result of IsOpenShift, nil until the cluster is discovered
+	openShift     *bool
+	openShiftLock sync.Mutex
 }
 
 // Check interface compliance.
@@ -87,6 +94,19 @@ func (c *defaultClient) GetCurrentNamespace(kubeConfig string) (string, error) {
 	return GetCurrentNamespace(kubeConfig)
 }
 
+func (c *defaultClient) IsOpenShift() (bool, error) {
+	c.openShiftLock.Lock()
+	defer c.openShiftLock.Unlock()
+	if c.openShift == nil {
+		result, err := openshift.IsOpenShift(c)
+		if err != nil {
+			return false, err
+		}
+		c.openShift = &result
+	}
+	return *c.openShift, nil
+}
+
 // NewOutOfClusterClient creates a new k8s client that can be used from outside the cluster.
 func NewOutOfClusterClient(kubeconfig string) (Client, error) {
 	initialize(kubeconfig)
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// testDiscovery fails the given number of times before discovering the cluster
+type testDiscovery struct {
+	discovery.DiscoveryInterface
+	failures int
+	calls    int
+}
+
+func (d *testDiscovery) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	d.calls++
+	if d.calls <= d.failures {
+		return nil, errors.New("connection refused")
+	}
+	return d.DiscoveryInterface.ServerResourcesForGroupVersion(groupVersion)
+}
+
+type testClientset struct {
+	kubernetes.Interface
+	discovery *testDiscovery
+}
+
+func (c testClientset) Discovery() discovery.DiscoveryInterface {
+	return c.discovery
+}
+
+func TestIsOpenShift(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.Resources = []*metav1.APIResourceList{{GroupVersion: "image.openshift.io/v1"}}
+	d := &testDiscovery{DiscoveryInterface: clientset.Discovery(), failures: 1}
+	c := &defaultClient{Interface: testClientset{Interface: clientset, discovery: d}}
+
+	// the errors are not cached
+	_, err := c.IsOpenShift()
+	assert.ErrorContains(t, err, "connection refused")
+	for i := 0; i < 2; i++ {
+		result, err := c.IsOpenShift()
+		assert.NoError(t, err)
+		assert.True(t, result)
+	}
+	// the cluster is discovered once per client
+	assert.Equal(t, 2, d.calls)
+
+	other := &defaultClient{Interface: fake.NewSimpleClientset()}
+	result, err := other.IsOpenShift()
+	assert.NoError(t, err)
+	assert.False(t, result)
+}
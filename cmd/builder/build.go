@@ -0,0 +1,312 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kiegroup/container-builder/api"
+	vanilla "github.com/kiegroup/container-builder/builder"
+	builder "github.com/kiegroup/container-builder/builder/kubernetes"
+	"github.com/kiegroup/container-builder/common"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	dockerfileName = "Dockerfile"
+	localDocker    = "docker"
+	localPodman    = "podman"
+)
+
+// stringsFlag a flag which can be repeated, collecting every value
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func runBuild(ctx context.Context, args []string, stdout, stderr io.Writer) (int, error) {
+	var cluster clusterFlags
+	var output outputFlags
+	var platformFile, dir, image, local string
+	var buildArgs, secretArgs, buildSecrets stringsFlag
+	var wait bool
+	var waitTimeout time.Duration
+	fs := newFlagSet("build", "NAME")
+	cluster.register(fs)
+	output.register(fs)
+	fs.StringVar(&platformFile, "platform", "", "path to the YAML or JSON PlatformBuild definition (required)")
+	fs.StringVar(&dir, "dir", ".", "directory holding the Dockerfile and the files added to the build context")
+	fs.StringVar(&image, "image", "", "name of the image to build (required)")
+	fs.Var(&buildArgs, "arg", "build argument as KEY=VALUE, can be repeated")
+	fs.Var(&secretArgs, "secret-arg", "build argument read from a Secret key as KEY=SECRET:SECRET_KEY, can be repeated")
+	fs.Var(&buildSecrets, "secret", "Secret mounted for the Dockerfile to read its files as NAME or NAME:DIR, in /kaniko/secrets/NAME by default, can be repeated")
+	fs.BoolVar(&wait, "wait", false, "wait for the build to finish")
+	fs.DurationVar(&waitTimeout, "wait-timeout", 0, "how long to wait for the build to finish, no limit by default")
+	fs.StringVar(&local, "local", "", "build locally with the given engine, one of docker or podman, instead of scheduling the build on the cluster")
+	if code, ok := parse(fs, args, stderr); !ok {
+		return code, nil
+	}
+	if fs.NArg() != 1 || platformFile == "" || image == "" {
+		fs.Usage()
+		return exitUsage, nil
+	}
+	if err := output.validate(); err != nil {
+		return exitUsage, err
+	}
+	name := fs.Arg(0)
+
+	platform, err := readPlatform(platformFile)
+	if err != nil {
+		return exitError, err
+	}
+	if local != "" {
+		if len(secretArgs) > 0 || len(buildSecrets) > 0 {
+			return exitUsage, errors.New("build secrets are not supported by local builds")
+		}
+		return runLocalBuild(ctx, local, dir, image, platform.Spec, buildArgs, stdout, stderr)
+	}
+	kanikoArgs, err := parseBuildArgs(buildArgs, secretArgs)
+	if err != nil {
+		return exitUsage, err
+	}
+	kanikoSecrets := parseBuildSecrets(buildSecrets)
+
+	resources, err := readResources(dir)
+	if err != nil {
+		return exitError, err
+	}
+	if cluster.namespace == "" {
+		cluster.namespace = platform.Namespace
+	}
+	store, err := cluster.store()
+	if err != nil {
+		return exitError, err
+	}
+	platform.Namespace = store.namespace
+
+	scheduler, err := builder.NewBuild(builder.BuilderInfo{FinalImageName: image, BuildUniqueName: name, Platform: *platform})
+	if err != nil {
+		return exitError, errors.Wrapf(err, "invalid build %s", name)
+	}
+	scheduler.WithClient(store.client)
+	if err := scheduler.Validate(ctx); err != nil {
+		return exitError, errors.Wrapf(err, "invalid build %s", name)
+	}
+
+	state, err := store.create(ctx, name)
+	if err != nil {
+		return exitError, err
+	}
+	scheduler.WithObjectDecorator(builder.OwnerReferenceDecorator(state, store.client.GetScheme()))
+	for target, content := range resources {
+		scheduler.WithResource(target, content)
+	}
+	scheduler.WithBuildArgs(kanikoArgs).WithBuildSecrets(kanikoSecrets)
+	build, err := scheduler.Schedule(ctx)
+	if err != nil {
+		// the build state owns whatever has been created so far
+		_ = store.delete(ctx, state)
+		return exitError, errors.Wrapf(err, "cannot schedule build %s", name)
+	}
+	if err := store.save(ctx, state, build); err != nil {
+		return exitError, err
+	}
+
+	if wait {
+		if waitTimeout > 0 {
+			var cancelTimeout context.CancelFunc
+			ctx, cancelTimeout = context.WithTimeout(ctx, waitTimeout)
+			defer cancelTimeout()
+		}
+		var saveErr, reconcileErr error
+		var lastStep string
+		updates, err := store.builder(state, build).Watch(ctx, func(target *api.Build, err error) {
+			if reconcileErr = err; err != nil {
+				return
+			}
+			saveErr = store.save(ctx, state, target)
+			if progress := target.Status.Progress; progress != nil && progress.Step != lastStep {
+				lastStep = progress.Step
+				fmt.Fprintln(stderr, formatProgress(name, progress))
+			}
+		})
+		if err != nil {
+			return exitError, err
+		}
+		for build = range updates {
+		}
+		if saveErr != nil {
+			return exitError, saveErr
+		}
+		if !build.Status.Phase.IsFinished() {
+			if reconcileErr != nil {
+				return exitError, errors.Wrapf(reconcileErr, "build %s not finished, check its status later on", name)
+			}
+			return exitError, errors.Errorf("build %s not finished, check its status later on", name)
+		}
+	}
+
+	if err := output.printBuild(stdout, build); err != nil {
+		return exitError, err
+	}
+	return exitCode(build.Status.Phase), nil
+}
+
+// runLocalBuild builds the image on the local Docker daemon with Kaniko or with the rootless Podman service
+func runLocalBuild(ctx context.Context, engine, dir, image string, platform api.PlatformBuildSpec, buildArgs []string, stdout, stderr io.Writer) (int, error) {
+	if _, err := os.Stat(filepath.Join(dir, dockerfileName)); err != nil {
+		return exitError, errors.Wrapf(err, "cannot find the %s", dockerfileName)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return exitError, err
+	}
+	var id string
+	switch engine {
+	case localDocker:
+		if len(buildArgs) > 0 {
+			return exitUsage, errors.New("build arguments are not supported by local Docker builds")
+		}
+		conn, err := common.GetDockerConnection()
+		if err != nil {
+			return exitError, errors.Wrap(err, "cannot connect to Docker")
+		}
+		id, err = vanilla.KanikoBuild(ctx, conn, vanilla.KanikoVanillaConfig{
+			DockerFilePath:         dir,
+			DockerFileName:         dockerfileName,
+			KanikoExecutorImage:    platform.GetExecutorImage(),
+			RegistryFinalImageName: image,
+			VerbosityLevel:         "info",
+			ReadBuildOutput:        true,
+			Mirrors:                platform.Registry.Mirrors,
+		})
+		if err != nil {
+			return exitFailed, errors.Wrapf(err, "local build of %s failed", image)
+		}
+		if err := (common.Docker{Connection: conn}).ContainerRemove(id); err != nil {
+			fmt.Fprintf(stderr, "WARNING: cannot remove the Kaniko container %s: %v\n", id, err)
+		}
+	case localPodman:
+		if len(buildArgs) > 0 {
+			return exitUsage, errors.New("build arguments are not supported by local Podman builds")
+		}
+		conn, err := common.GetRootlessPodmanConnection()
+		if err != nil {
+			return exitError, errors.Wrap(err, "cannot connect to Podman")
+		}
+		id, err = vanilla.BuildahBuild(ctx, conn, vanilla.BuildahVanillaConfig{
+			DockerFilePath: dir + string(filepath.Separator),
+			DockerFileName: dockerfileName,
+			Tags:           []string{image},
+			Mirrors:        platform.Registry.Mirrors,
+		})
+		if err != nil {
+			return exitFailed, errors.Wrapf(err, "local build of %s failed", image)
+		}
+	default:
+		return exitUsage, errors.Errorf("unsupported local engine %s, must be one of docker or podman", engine)
+	}
+	fmt.Fprintf(stdout, "Image %s built locally with %s: %s\n", image, engine, id)
+	return exitOK, nil
+}
+
+// parseBuildArgs returns the literal build arguments given as KEY=VALUE and the ones read from Secrets given as KEY=SECRET:SECRET_KEY
+func parseBuildArgs(literals, secretRefs []string) ([]api.BuildArg, error) {
+	var args []api.BuildArg
+	for _, literal := range literals {
+		name, value, _ := strings.Cut(literal, "=")
+		args = append(args, api.BuildArg{Name: name, Value: value})
+	}
+	for _, secretRef := range secretRefs {
+		name, ref, _ := strings.Cut(secretRef, "=")
+		secret, key, ok := strings.Cut(ref, ":")
+		if !ok {
+			return nil, errors.Errorf("invalid build argument %s, must be KEY=SECRET:SECRET_KEY", secretRef)
+		}
+		args = append(args, api.BuildArg{Name: name, ValueFrom: &api.BuildArgSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: secret},
+			Key:                  key,
+		}}})
+	}
+	return args, nil
+}
+
+// parseBuildSecrets returns the build secrets given as NAME or NAME:DIR
+func parseBuildSecrets(values []string) []api.BuildSecret {
+	var secrets []api.BuildSecret
+	for _, value := range values {
+		name, dir, _ := strings.Cut(value, ":")
+		secrets = append(secrets, api.BuildSecret{Name: name, MountPath: dir})
+	}
+	return secrets
+}
+
+func readPlatform(path string) (*api.PlatformBuild, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read the PlatformBuild")
+	}
+	defer file.Close()
+	platforms, err := builder.LoadPlatformBuilds(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot load the PlatformBuild %s", path)
+	}
+	if len(platforms) != 1 {
+		return nil, errors.Errorf("%s must define exactly one PlatformBuild, found %d", path, len(platforms))
+	}
+	return &platforms[0], nil
+}
+
+// readResources reads the regular files in the given directory, the Dockerfile is required.
+// The subdirectories are rejected since the resources are mounted from a ConfigMap, whose keys can't be paths.
+func readResources(dir string) (map[string][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read the build directory")
+	}
+	resources := map[string][]byte{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			return nil, errors.Errorf("the build directory %s holds the subdirectory %s, the build files must be at its top level", dir, entry.Name())
+		}
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		resources[entry.Name()] = content
+	}
+	if _, ok := resources[dockerfileName]; !ok {
+		return nil, errors.Errorf("no %s found in %s", dockerfileName, dir)
+	}
+	return resources, nil
+}
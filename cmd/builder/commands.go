@@ -0,0 +1,283 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/kiegroup/container-builder/api"
+	builder "github.com/kiegroup/container-builder/builder/kubernetes"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// parse parses the command flags, printing their usage and errors to stderr.
+// When the command must not run, false is returned along with its exit code.
+func parse(fs *flag.FlagSet, args []string, stderr io.Writer) (int, bool) {
+	fs.SetOutput(stderr)
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+func newFlagSet(name, arguments string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: builder %s [flags] %s\n", name, arguments)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func runStatus(ctx context.Context, args []string, stdout, stderr io.Writer) (int, error) {
+	var cluster clusterFlags
+	var output outputFlags
+	fs := newFlagSet("status", "NAME")
+	cluster.register(fs)
+	output.register(fs)
+	if code, ok := parse(fs, args, stderr); !ok {
+		return code, nil
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitUsage, nil
+	}
+	if err := output.validate(); err != nil {
+		return exitUsage, err
+	}
+	store, err := cluster.store()
+	if err != nil {
+		return exitError, err
+	}
+	state, build, err := store.get(ctx, fs.Arg(0))
+	if err != nil {
+		return exitError, err
+	}
+	if !build.Status.Phase.IsFinished() {
+		if build, err = store.builder(state, build).Reconcile(ctx); err != nil {
+			return exitError, errors.Wrapf(err, "cannot reconcile build %s", fs.Arg(0))
+		}
+		if err := store.save(ctx, state, build); err != nil {
+			return exitError, err
+		}
+	}
+	if err := output.printBuild(stdout, build); err != nil {
+		return exitError, err
+	}
+	return exitCode(build.Status.Phase), nil
+}
+
+func runLogs(ctx context.Context, args []string, stdout, stderr io.Writer) (int, error) {
+	var cluster clusterFlags
+	var follow bool
+	fs := newFlagSet("logs", "NAME")
+	cluster.register(fs)
+	fs.BoolVar(&follow, "f", false, "follow the logs until the build is finished")
+	if code, ok := parse(fs, args, stderr); !ok {
+		return code, nil
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitUsage, nil
+	}
+	store, err := cluster.store()
+	if err != nil {
+		return exitError, err
+	}
+	state, build, err := store.get(ctx, fs.Arg(0))
+	if err != nil {
+		return exitError, err
+	}
+	logs, err := store.builder(state, build).Logs(ctx, follow)
+	if err != nil {
+		return exitError, err
+	}
+	defer logs.Close()
+	if _, err := io.Copy(stdout, logs); err != nil {
+		return exitError, err
+	}
+	return exitOK, nil
+}
+
+func runCancel(ctx context.Context, args []string, stdout, stderr io.Writer) (int, error) {
+	var cluster clusterFlags
+	var output outputFlags
+	fs := newFlagSet("cancel", "NAME")
+	cluster.register(fs)
+	output.register(fs)
+	if code, ok := parse(fs, args, stderr); !ok {
+		return code, nil
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitUsage, nil
+	}
+	if err := output.validate(); err != nil {
+		return exitUsage, err
+	}
+	store, err := cluster.store()
+	if err != nil {
+		return exitError, err
+	}
+	state, build, err := store.get(ctx, fs.Arg(0))
+	if err != nil {
+		return exitError, err
+	}
+	if build.Status.Phase.IsFinished() {
+		return exitError, errors.Errorf("build %s is already finished with phase %s", build.Name, build.Status.Phase)
+	}
+	if build, err = store.builder(state, build).CancelBuild(ctx); err != nil {
+		return exitError, errors.Wrapf(err, "cannot cancel build %s", fs.Arg(0))
+	}
+	if err := store.save(ctx, state, build); err != nil {
+		return exitError, err
+	}
+	if err := output.printBuild(stdout, build); err != nil {
+		return exitError, err
+	}
+	return exitOK, nil
+}
+
+func runList(ctx context.Context, args []string, stdout, stderr io.Writer) (int, error) {
+	var cluster clusterFlags
+	var output outputFlags
+	fs := newFlagSet("list", "")
+	cluster.register(fs)
+	output.register(fs)
+	if code, ok := parse(fs, args, stderr); !ok {
+		return code, nil
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return exitUsage, nil
+	}
+	if err := output.validate(); err != nil {
+		return exitUsage, err
+	}
+	store, err := cluster.store()
+	if err != nil {
+		return exitError, err
+	}
+	_, builds, err := store.list(ctx)
+	if err != nil {
+		return exitError, err
+	}
+	if err := output.printBuilds(stdout, builds); err != nil {
+		return exitError, err
+	}
+	return exitOK, nil
+}
+
+func runStale(ctx context.Context, args []string, stdout, stderr io.Writer) (int, error) {
+	var cluster clusterFlags
+	var output outputFlags
+	fs := newFlagSet("stale", "")
+	cluster.register(fs)
+	output.register(fs)
+	if code, ok := parse(fs, args, stderr); !ok {
+		return code, nil
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return exitUsage, nil
+	}
+	if err := output.validate(); err != nil {
+		return exitUsage, err
+	}
+	store, err := cluster.store()
+	if err != nil {
+		return exitError, err
+	}
+	_, builds, err := store.list(ctx)
+	if err != nil {
+		return exitError, err
+	}
+	stale, err := builder.FindStaleBuilds(ctx, store.client, builds)
+	if err != nil {
+		return exitError, err
+	}
+	if err := output.printStaleBuilds(stdout, stale); err != nil {
+		return exitError, err
+	}
+	return exitOK, nil
+}
+
+func runClean(ctx context.Context, args []string, stdout, stderr io.Writer) (int, error) {
+	var cluster clusterFlags
+	var all bool
+	fs := newFlagSet("clean", "[NAME...]")
+	cluster.register(fs)
+	fs.BoolVar(&all, "all", false, "cancel and delete the running builds too")
+	if code, ok := parse(fs, args, stderr); !ok {
+		return code, nil
+	}
+	store, err := cluster.store()
+	if err != nil {
+		return exitError, err
+	}
+	var states []*corev1.ConfigMap
+	var builds []*api.Build
+	if fs.NArg() > 0 {
+		for _, name := range fs.Args() {
+			state, build, err := store.get(ctx, name)
+			if err != nil {
+				return exitError, err
+			}
+			states = append(states, state)
+			builds = append(builds, build)
+		}
+	} else if states, builds, err = store.list(ctx); err != nil {
+		return exitError, err
+	}
+	for i, build := range builds {
+		if !isTerminated(build) {
+			if !all {
+				fmt.Fprintf(stdout, "Build %s is still running, skipped\n", build.Name)
+				continue
+			}
+			if _, err := store.builder(states[i], build).CancelBuild(ctx); err != nil {
+				return exitError, errors.Wrapf(err, "cannot cancel build %s", build.Name)
+			}
+		}
+		if err := store.delete(ctx, states[i]); err != nil {
+			return exitError, errors.Wrapf(err, "cannot delete build %s", build.Name)
+		}
+		fmt.Fprintf(stdout, "Build %s deleted\n", build.Name)
+	}
+	return exitOK, nil
+}
+
+// isTerminated returns true if the build won't run anymore: finished, or failed with no recovery attempt left.
+// The failed builds are retried by the error recovery otherwise.
+func isTerminated(build *api.Build) bool {
+	switch build.Status.Phase {
+	case api.BuildPhaseSucceeded, api.BuildPhaseError, api.BuildPhaseInterrupted:
+		return true
+	case api.BuildPhaseFailed:
+		failure := build.Status.Failure
+		return failure != nil && failure.Recovery.Attempt >= failure.Recovery.AttemptMax
+	default:
+		return false
+	}
+}
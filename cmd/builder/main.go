@@ -0,0 +1,108 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Command builder schedules and inspects container image builds on Kubernetes, or runs them locally with Docker or Podman.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/util/log"
+)
+
+// Exit codes returned by the commands. Commands reporting a Build exit with the code matching its phase.
+const (
+	exitOK          = 0
+	exitError       = 1
+	exitUsage       = 2
+	exitFailed      = 3
+	exitErrored     = 4
+	exitInterrupted = 5
+)
+
+type command struct {
+	name        string
+	description string
+	run         func(ctx context.Context, args []string, stdout, stderr io.Writer) (int, error)
+}
+
+var commands = []command{
+	{"build", "schedule a new build from a directory and a PlatformBuild definition", runBuild},
+	{"status", "reconcile and show the status of a build", runStatus},
+	{"logs", "print the logs of a build", runLogs},
+	{"cancel", "cancel a running build", runCancel},
+	{"list", "list the builds", runList},
+	{"stale", "list the builds whose pinned base images moved since they were built", runStale},
+	{"rebuild", "periodically rebuild the builds whose pinned base images moved", runRebuild},
+	{"clean", "delete the finished builds and every object they created", runClean},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitUsage
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			// an interrupt stops the command, cancelling the calls to the cluster
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+			code, err := cmd.run(ctx, args[1:], stdout, stderr)
+			if err != nil {
+				fmt.Fprintf(stderr, "Error: %v\n", log.DefaultRedactor().RedactError(err))
+			}
+			return code
+		}
+	}
+	usage(stderr)
+	return exitUsage
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: builder <command> [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'builder <command> -h' for the command flags.")
+	fmt.Fprintf(w, "Commands reporting a build exit with %d if it succeeded or is still running, %d if it failed, %d if it errored and %d if it was interrupted.\n",
+		exitOK, exitFailed, exitErrored, exitInterrupted)
+}
+
+// exitCode returns the exit code matching the given build phase
+func exitCode(phase api.BuildPhase) int {
+	switch phase {
+	case api.BuildPhaseFailed:
+		return exitFailed
+	case api.BuildPhaseError:
+		return exitErrored
+	case api.BuildPhaseInterrupted:
+		return exitInterrupted
+	default:
+		return exitOK
+	}
+}
@@ -0,0 +1,192 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/client"
+	"github.com/kiegroup/container-builder/util/test"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const testNamespace = "test"
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	platformFile := filepath.Join(dir, "platform.yaml")
+	assert.NoError(t, os.WriteFile(platformFile, []byte(`
+meta:
+  name: platform
+spec:
+  publishStrategy: Kaniko
+  registry:
+    address: quay.io/kiegroup
+`), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, dockerfileName), []byte("FROM busybox"), 0o600))
+
+	tests := []struct {
+		name     string
+		args     []string
+		objects  []runtime.Object
+		code     int
+		stdout   string
+		stderr   string
+		setup    func(t *testing.T)
+		validate func(t *testing.T, c client.Client)
+	}{
+		{name: "no command", code: exitUsage, stderr: "Usage: builder <command>"},
+		{name: "unknown command", args: []string{"unknown"}, code: exitUsage, stderr: "Usage: builder <command>"},
+		{name: "help", args: []string{"status", "-h"}, code: exitOK, stderr: "Usage: builder status [flags] NAME"},
+		{name: "unknown flag", args: []string{"status", "-unknown"}, code: exitUsage, stderr: "flag provided but not defined: -unknown"},
+		{name: "missing argument", args: []string{"status", "-namespace", testNamespace}, code: exitUsage, stderr: "Usage: builder status [flags] NAME"},
+		{name: "invalid output", args: []string{"list", "-o", "xml"}, code: exitUsage, stderr: "Error: unsupported output format xml"},
+		{name: "missing platform", args: []string{"build", "-image", "quay.io/kiegroup/test:latest", "-platform", filepath.Join(dir, "missing.yaml"), "build1"},
+			code: exitError, stderr: "Error: cannot read the PlatformBuild"},
+		{name: "unknown build", args: []string{"status", "-namespace", testNamespace, "unknown"},
+			code: exitError, stderr: "Error: build unknown not found in namespace test"},
+		{name: "succeeded build", args: []string{"status", "-namespace", testNamespace, "build1"},
+			objects: []runtime.Object{newTestState(t, "build1", api.BuildPhaseSucceeded)}, code: exitOK, stdout: "build1"},
+		{name: "failed build", args: []string{"status", "-namespace", testNamespace, "build1"},
+			objects: []runtime.Object{newTestState(t, "build1", api.BuildPhaseFailed)}, code: exitFailed, stdout: "Failed"},
+		{name: "errored build", args: []string{"status", "-namespace", testNamespace, "build1"},
+			objects: []runtime.Object{newTestState(t, "build1", api.BuildPhaseError)}, code: exitErrored, stdout: "Error"},
+		{name: "interrupted build", args: []string{"status", "-namespace", testNamespace, "build1"},
+			objects: []runtime.Object{newTestState(t, "build1", api.BuildPhaseInterrupted)}, code: exitInterrupted, stdout: "Interrupted"},
+		{name: "cancel finished build", args: []string{"cancel", "-namespace", testNamespace, "build1"},
+			objects: []runtime.Object{newTestState(t, "build1", api.BuildPhaseSucceeded)}, code: exitError, stderr: "Error: build build1 is already finished"},
+		{name: "list", args: []string{"list", "-namespace", testNamespace, "-o", "json"}, code: exitOK, stdout: `"name": "build2"`,
+			objects: []runtime.Object{newTestState(t, "build1", api.BuildPhaseSucceeded), newTestState(t, "build2", api.BuildPhaseFailed)}},
+		{name: "clean", args: []string{"clean", "-namespace", testNamespace},
+			objects: []runtime.Object{newTestState(t, "build1", api.BuildPhaseSucceeded)}, code: exitOK, stdout: "Build build1 deleted",
+			validate: func(t *testing.T, c client.Client) {
+				states := &corev1.ConfigMapList{}
+				assert.NoError(t, c.List(context.TODO(), states))
+				assert.Empty(t, states.Items)
+			}},
+		{name: "clean failed builds", args: []string{"clean", "-namespace", testNamespace},
+			objects: []runtime.Object{
+				newTestState(t, "build1", api.BuildPhaseFailed),
+				newTestFailureState(t, "build2", api.FailureRecovery{Attempt: 2, AttemptMax: 5}),
+				newTestFailureState(t, "build3", api.FailureRecovery{Attempt: 5, AttemptMax: 5}),
+			}, code: exitOK, stdout: "Build build3 deleted",
+			validate: func(t *testing.T, c client.Client) {
+				// the failed builds are deleted once no recovery attempt is left
+				states := &corev1.ConfigMapList{}
+				assert.NoError(t, c.List(context.TODO(), states))
+				assert.Len(t, states.Items, 2)
+				for _, state := range states.Items {
+					assert.NotEqual(t, "build3", state.Labels[buildLabel])
+				}
+			}},
+		{name: "build subdirectory", args: []string{"build", "-namespace", testNamespace, "-platform", platformFile, "-dir", dir, "-image", "quay.io/kiegroup/test:latest", "build1"},
+			setup: func(t *testing.T) {
+				sub := filepath.Join(dir, "sub")
+				assert.NoError(t, os.Mkdir(sub, 0o700))
+				t.Cleanup(func() { assert.NoError(t, os.Remove(sub)) })
+			}, code: exitError, stderr: "holds the subdirectory sub"},
+		{name: "build", args: []string{"build", "-namespace", testNamespace, "-platform", platformFile, "-dir", dir, "-image", "quay.io/kiegroup/test:latest", "build1"},
+			code: exitOK, stdout: "build1",
+			validate: func(t *testing.T, c client.Client) {
+				state := &corev1.ConfigMap{}
+				assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: stateName("build1")}, state))
+				assert.Equal(t, "build1", state.Labels[buildLabel])
+				build, err := decodeBuild(state)
+				assert.NoError(t, err)
+				assert.Equal(t, "build1", build.Name)
+				assert.Equal(t, api.BuildPhaseScheduling, build.Status.Phase)
+			}},
+		{name: "build with secrets", args: []string{"build", "-namespace", testNamespace, "-platform", platformFile, "-dir", dir, "-image", "quay.io/kiegroup/test:latest",
+			"-arg", "API_TOKEN=s3cr3t", "-o", "json", "build1"}, code: exitOK, stdout: `"value": "***"`,
+			validate: func(t *testing.T, c client.Client) {
+				state := &corev1.ConfigMap{}
+				assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: stateName("build1")}, state))
+				assert.NotContains(t, state.Data[buildStateKey], "s3cr3t")
+				// the secret values are read back from the Secret of the build spec
+				store := &buildStore{client: c, namespace: testNamespace}
+				_, build, err := store.get(context.TODO(), "build1")
+				assert.NoError(t, err)
+				assert.Contains(t, build.Spec.Tasks[0].Kaniko.BuildArgs, api.BuildArg{Name: "API_TOKEN", Value: "s3cr3t"})
+			}},
+		{name: "build already exists", args: []string{"build", "-namespace", testNamespace, "-platform", platformFile, "-dir", dir, "-image", "quay.io/kiegroup/test:latest", "build1"},
+			objects: []runtime.Object{newTestState(t, "build1", api.BuildPhaseSucceeded)}, code: exitError, stderr: "Error: build build1 already exists in namespace test"},
+	}
+	defer func(original func(string) (client.Client, error)) { newClient = original }(newClient)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := test.NewFakeClient(tt.objects...)
+			assert.NoError(t, err)
+			newClient = func(string) (client.Client, error) {
+				return c, nil
+			}
+			if tt.setup != nil {
+				tt.setup(t)
+			}
+			var stdout, stderr bytes.Buffer
+			assert.Equal(t, tt.code, run(tt.args, &stdout, &stderr), "stderr: %s", stderr.String())
+			assert.Contains(t, stdout.String(), tt.stdout)
+			assert.Contains(t, stderr.String(), tt.stderr)
+			assert.NotContains(t, stdout.String()+stderr.String(), "s3cr3t")
+			if tt.validate != nil {
+				tt.validate(t, c)
+			}
+		})
+	}
+}
+
+// newTestState returns the ConfigMap storing a build with the given phase
+func newTestState(t *testing.T, name string, phase api.BuildPhase) *corev1.ConfigMap {
+	return newTestBuildState(t, &api.Build{
+		ObjectReference: api.ObjectReference{Namespace: testNamespace, Name: name},
+		Status:          api.BuildStatus{Phase: phase},
+	})
+}
+
+// newTestFailureState returns the ConfigMap storing a failed build with the given recovery attempts
+func newTestFailureState(t *testing.T, name string, recovery api.FailureRecovery) *corev1.ConfigMap {
+	return newTestBuildState(t, &api.Build{
+		ObjectReference: api.ObjectReference{Namespace: testNamespace, Name: name},
+		Status: api.BuildStatus{
+			Phase:   api.BuildPhaseFailed,
+			Failure: &api.Failure{Reason: "builder Pod failed", Recovery: recovery},
+		},
+	})
+}
+
+// newTestBuildState returns the ConfigMap storing the given build
+func newTestBuildState(t *testing.T, build *api.Build) *corev1.ConfigMap {
+	data, err := json.Marshal(build)
+	assert.NoError(t, err)
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      stateName(build.Name),
+			Labels:    map[string]string{componentLabel: component, buildLabel: build.Name},
+		},
+		Data: map[string]string{buildStateKey: string(data)},
+	}
+}
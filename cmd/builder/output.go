@@ -0,0 +1,133 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/kiegroup/container-builder/api"
+	builder "github.com/kiegroup/container-builder/builder/kubernetes"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// outputFlags flags shared by the commands printing builds
+type outputFlags struct {
+	format string
+}
+
+func (f *outputFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.format, "o", outputTable, "output format, one of table, json or yaml")
+}
+
+func (f *outputFlags) validate() error {
+	switch f.format {
+	case outputTable, outputJSON, outputYAML:
+		return nil
+	default:
+		return errors.Errorf("unsupported output format %s, must be one of table, json or yaml", f.format)
+	}
+}
+
+// printBuild prints a single build in the selected format, with its secret values masked
+func (f *outputFlags) printBuild(w io.Writer, build *api.Build) error {
+	build = builder.RedactBuild(build)
+	if f.format == outputTable {
+		return printTable(w, []*api.Build{build})
+	}
+	return f.marshal(w, build)
+}
+
+// printBuilds prints a list of builds in the selected format, with their secret values masked
+func (f *outputFlags) printBuilds(w io.Writer, builds []*api.Build) error {
+	redacted := make([]*api.Build, 0, len(builds))
+	for _, build := range builds {
+		redacted = append(redacted, builder.RedactBuild(build))
+	}
+	builds = redacted
+	if f.format == outputTable {
+		return printTable(w, builds)
+	}
+	return f.marshal(w, builds)
+}
+
+// printStaleBuilds prints the builds whose base images moved in the selected format, with their secret values masked
+func (f *outputFlags) printStaleBuilds(w io.Writer, stale []builder.StaleBuild) error {
+	redacted := make([]builder.StaleBuild, 0, len(stale))
+	for _, s := range stale {
+		s.Build = builder.RedactBuild(s.Build)
+		redacted = append(redacted, s)
+	}
+	stale = redacted
+	if f.format == outputTable {
+		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tNAMESPACE\tIMAGE\tBASE IMAGE\tCURRENT DIGEST")
+		for _, s := range stale {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.Build.Name, s.Build.Namespace, s.Build.Status.Image, s.BaseImage, s.CurrentDigest)
+		}
+		return tw.Flush()
+	}
+	return f.marshal(w, stale)
+}
+
+func (f *outputFlags) marshal(w io.Writer, value interface{}) error {
+	var data []byte
+	var err error
+	if f.format == outputJSON {
+		data, err = json.MarshalIndent(value, "", "  ")
+		data = append(data, '\n')
+	} else {
+		data, err = yaml.Marshal(value)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func printTable(w io.Writer, builds []*api.Build) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tNAMESPACE\tPHASE\tIMAGE\tDURATION\tERROR")
+	for _, build := range builds {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			build.Name, build.Namespace, build.Status.Phase, build.Status.Image, build.Status.Duration, build.Status.Error)
+	}
+	return tw.Flush()
+}
+
+// formatProgress formats the current step of the build, like "build1: step 3/14 RUN mvn package"
+func formatProgress(name string, progress *api.BuildProgress) string {
+	switch {
+	case progress.StepNumber > 0 && progress.TotalSteps > 0:
+		return fmt.Sprintf("%s: step %d/%d %s", name, progress.StepNumber, progress.TotalSteps, progress.Step)
+	case progress.StepNumber > 0:
+		return fmt.Sprintf("%s: step %d %s", name, progress.StepNumber, progress.Step)
+	default:
+		return fmt.Sprintf("%s: %s", name, progress.Step)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/kiegroup/container-builder/api"
+	builder "github.com/kiegroup/container-builder/builder/kubernetes"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// rebuildSuffix the suffix of the rebuilds names, replaced upon every rebuild to keep the names short
+var rebuildSuffix = regexp.MustCompile(`-r[0-9]+$`)
+
+func runRebuild(ctx context.Context, args []string, stdout, stderr io.Writer) (int, error) {
+	var cluster clusterFlags
+	var interval time.Duration
+	var concurrency int
+	var dryRun, once bool
+	fs := newFlagSet("rebuild", "")
+	cluster.register(fs)
+	fs.DurationVar(&interval, "interval", builder.DefaultRebuildInterval, "how often the base images are checked")
+	fs.IntVar(&concurrency, "concurrency", 1, "how many rebuilds can run at the same time, no limit if 0")
+	fs.BoolVar(&dryRun, "dry-run", false, "only report the builds whose base images moved")
+	fs.BoolVar(&once, "once", false, "check the base images once and exit")
+	if code, ok := parse(fs, args, stderr); !ok {
+		return code, nil
+	}
+	if fs.NArg() != 0 || interval <= 0 || concurrency < 0 {
+		fs.Usage()
+		return exitUsage, nil
+	}
+	store, err := cluster.store()
+	if err != nil {
+		return exitError, err
+	}
+
+	// the running builds are reconciled, for the watcher to know when its rebuilds are finished
+	builds := func(ctx context.Context) ([]*api.Build, error) {
+		states, builds, err := store.list(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for i, build := range builds {
+			if build.Status.Phase.IsFinished() {
+				continue
+			}
+			if builds[i], err = store.builder(states[i], build).Reconcile(ctx); err != nil {
+				return nil, errors.Wrapf(err, "cannot reconcile build %s", build.Name)
+			}
+			if err := store.save(ctx, states[i], builds[i]); err != nil {
+				return nil, err
+			}
+		}
+		return builds, nil
+	}
+	rebuild := func(ctx context.Context, stale builder.StaleBuild) (*api.Build, error) {
+		name := fmt.Sprintf("%s-r%d", rebuildSuffix.ReplaceAllString(stale.Build.Name, ""), time.Now().Unix())
+		state, err := store.create(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		build, err := scheduleRebuild(ctx, store, state, stale.Build, name)
+		if err != nil {
+			// the build state owns whatever has been created so far
+			_ = store.delete(ctx, state)
+			return nil, err
+		}
+		fmt.Fprintf(stdout, "Build %s rebuilt by %s, base image %s moved to %s\n", stale.Build.Name, name, stale.BaseImage, stale.CurrentDigest)
+		return build, nil
+	}
+
+	watcher := builder.NewRebuildWatcher(store.client, builds, rebuild)
+	watcher.Interval = interval
+	watcher.Concurrency = concurrency
+	watcher.DryRun = dryRun
+	if !once {
+		return exitOK, watcher.Start(ctx)
+	}
+	stale, err := watcher.Check(ctx)
+	if err != nil {
+		return exitError, err
+	}
+	if dryRun {
+		for _, s := range stale {
+			fmt.Fprintf(stdout, "Build %s is stale, base image %s moved to %s\n", s.Build.Name, s.BaseImage, s.CurrentDigest)
+		}
+	}
+	return exitOK, nil
+}
+
+func scheduleRebuild(ctx context.Context, store *buildStore, state *corev1.ConfigMap, build *api.Build, name string) (*api.Build, error) {
+	scheduler, err := builder.RebuildOf(ctx, store.client, build, name)
+	if err != nil {
+		return nil, err
+	}
+	scheduler.WithObjectDecorator(builder.OwnerReferenceDecorator(state, store.client.GetScheme()))
+	rebuild, err := scheduler.Schedule(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot schedule build %s", name)
+	}
+	return rebuild, store.save(ctx, state, rebuild)
+}
@@ -0,0 +1,241 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/kiegroup/container-builder/api"
+	builder "github.com/kiegroup/container-builder/builder/kubernetes"
+	"github.com/kiegroup/container-builder/client"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	componentLabel = "kie.kogito.org/component"
+	component      = "builder-cli"
+	buildLabel     = "kie.kogito.org/buildContext"
+	buildStateKey  = "build.json"
+	buildStateName = "%s-build-state"
+	// buildSpecAnnotation the annotation of the build states naming the Secret which keeps the build spec, see buildStore.save
+	buildSpecAnnotation = "kie.kogito.org/buildSpecSecret"
+	buildSpecKey        = "spec.json"
+)
+
+// newClient creates the client of the cluster from the kubeconfig file, replaced by the tests
+var newClient = client.NewOutOfClusterClient
+
+// clusterFlags flags shared by the commands connecting to the cluster
+type clusterFlags struct {
+	namespace  string
+	kubeconfig string
+}
+
+func (f *clusterFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.namespace, "namespace", "", "namespace of the build, defaults to the current namespace")
+	fs.StringVar(&f.kubeconfig, "kubeconfig", "", "path to the kubeconfig file, defaults to $KUBECONFIG or ~/.kube/config")
+}
+
+// store connects to the cluster and returns the store of the builds in the selected namespace
+func (f *clusterFlags) store() (*buildStore, error) {
+	c, err := newClient(f.kubeconfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot connect to the cluster")
+	}
+	namespace := f.namespace
+	if namespace == "" {
+		if namespace, err = c.GetCurrentNamespace(f.kubeconfig); err != nil {
+			return nil, errors.Wrap(err, "cannot find the current namespace")
+		}
+	}
+	return &buildStore{client: c, namespace: namespace}, nil
+}
+
+// buildStore keeps the builds scheduled by the command line in ConfigMaps, so they can be inspected later on.
+// Every object created for a build is owned by its ConfigMap and garbage collected along with it, like the Secret keeping
+// the spec of the builds with secret values.
+type buildStore struct {
+	client    client.Client
+	namespace string
+}
+
+// create creates the ConfigMap holding the state of a new build
+func (s *buildStore) create(ctx context.Context, name string) (*corev1.ConfigMap, error) {
+	state := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: s.namespace,
+			Name:      stateName(name),
+			Labels: map[string]string{
+				componentLabel: component,
+				buildLabel:     name,
+			},
+		},
+	}
+	if err := s.client.Create(ctx, state); err != nil {
+		if k8serrors.IsAlreadyExists(err) {
+			return nil, errors.Errorf("build %s already exists in namespace %s", name, s.namespace)
+		}
+		return nil, err
+	}
+	return state, nil
+}
+
+func (s *buildStore) get(ctx context.Context, name string) (*corev1.ConfigMap, *api.Build, error) {
+	state := &corev1.ConfigMap{}
+	if err := s.client.Get(ctx, types.NamespacedName{Namespace: s.namespace, Name: stateName(name)}, state); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil, errors.Errorf("build %s not found in namespace %s", name, s.namespace)
+		}
+		return nil, nil, err
+	}
+	build, err := decodeBuild(state)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.restoreSpec(ctx, state, build); err != nil {
+		return nil, nil, err
+	}
+	return state, build, nil
+}
+
+func (s *buildStore) list(ctx context.Context) ([]*corev1.ConfigMap, []*api.Build, error) {
+	states := &corev1.ConfigMapList{}
+	if err := s.client.List(ctx, states, ctrl.InNamespace(s.namespace), ctrl.MatchingLabels{componentLabel: component}); err != nil {
+		return nil, nil, err
+	}
+	var configMaps []*corev1.ConfigMap
+	var builds []*api.Build
+	for i := range states.Items {
+		build, err := decodeBuild(&states.Items[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := s.restoreSpec(ctx, &states.Items[i], build); err != nil {
+			return nil, nil, err
+		}
+		configMaps = append(configMaps, &states.Items[i])
+		builds = append(builds, build)
+	}
+	return configMaps, builds, nil
+}
+
+// save stores the build in its state with the secret values masked. The build spec holding them, like the values of the secret
+// build arguments, is kept in a Secret owned by the state, read back by get and list.
+func (s *buildStore) save(ctx context.Context, state *corev1.ConfigMap, build *api.Build) error {
+	redacted := builder.RedactBuild(build)
+	if !equality.Semantic.DeepEqual(build.Spec, redacted.Spec) {
+		if err := s.saveSpec(ctx, state, build); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(redacted)
+	if err != nil {
+		return err
+	}
+	if state.Data == nil {
+		state.Data = map[string]string{}
+	}
+	state.Data[buildStateKey] = string(data)
+	return s.client.Update(ctx, state)
+}
+
+// saveSpec keeps the build spec in a Secret named after the state, the spec of a build never changes once it's created
+func (s *buildStore) saveSpec(ctx context.Context, state *corev1.ConfigMap, build *api.Build) error {
+	data, err := json.Marshal(build.Spec)
+	if err != nil {
+		return err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: state.Namespace,
+			Name:      state.Name,
+			Labels:    state.Labels,
+		},
+		Data: map[string][]byte{buildSpecKey: data},
+	}
+	if err := builder.OwnerReferenceDecorator(state, s.client.GetScheme())(secret); err != nil {
+		return err
+	}
+	if err := s.client.Create(ctx, secret); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "cannot store the secrets of build %s", build.Name)
+	}
+	if state.Annotations == nil {
+		state.Annotations = map[string]string{}
+	}
+	state.Annotations[buildSpecAnnotation] = secret.Name
+	return nil
+}
+
+// restoreSpec restores the build spec kept in a Secret by save, if any
+func (s *buildStore) restoreSpec(ctx context.Context, state *corev1.ConfigMap, build *api.Build) error {
+	name, ok := state.Annotations[buildSpecAnnotation]
+	if !ok {
+		return nil
+	}
+	secret := &corev1.Secret{}
+	if err := s.client.Get(ctx, types.NamespacedName{Namespace: state.Namespace, Name: name}, secret); err != nil {
+		return errors.Wrapf(err, "cannot read the secrets of build %s", build.Name)
+	}
+	if err := json.Unmarshal(secret.Data[buildSpecKey], &build.Spec); err != nil {
+		return errors.Wrapf(err, "cannot read the secrets of build %s", build.Name)
+	}
+	return nil
+}
+
+// delete deletes the build state, the Kubernetes garbage collector takes care of the objects it owns
+func (s *buildStore) delete(ctx context.Context, state *corev1.ConfigMap) error {
+	err := s.client.Delete(ctx, state, ctrl.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err != nil && k8serrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// builder returns the Builder of a stored build, making every object it creates owned by the build state
+func (s *buildStore) builder(state *corev1.ConfigMap, build *api.Build) builder.Builder {
+	return builder.FromBuild(build).
+		WithClient(s.client).
+		WithObjectDecorator(builder.OwnerReferenceDecorator(state, s.client.GetScheme()))
+}
+
+func stateName(name string) string {
+	return fmt.Sprintf(buildStateName, name)
+}
+
+func decodeBuild(state *corev1.ConfigMap) (*api.Build, error) {
+	build := &api.Build{}
+	data, ok := state.Data[buildStateKey]
+	if !ok {
+		// the build has not been scheduled yet
+		build.Name = state.Labels[buildLabel]
+		build.Namespace = state.Namespace
+		return build, nil
+	}
+	if err := json.Unmarshal([]byte(data), build); err != nil {
+		return nil, errors.Wrapf(err, "cannot read the state of build %s", state.Labels[buildLabel])
+	}
+	return build, nil
+}
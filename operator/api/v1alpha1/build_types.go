@@ -0,0 +1,102 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1alpha1
+
+import (
+	"github.com/kiegroup/container-builder/api"
+	"github.com/kiegroup/container-builder/util/defaults"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BuildSpec defines the desired state of a Build Custom Resource.
+// The InputCache and PinBaseImages options of api.BuildSpec are left out, since the steps run by the Scheduler before
+// the build starts are skipped, so that the API server rejects them instead of the builds ignoring them.
+type BuildSpec struct {
+	// The sequence of Build tasks to be performed as part of the Build execution.
+	Tasks []api.Task `json:"tasks,omitempty"`
+	// The strategy that should be used to perform the Build.
+	Strategy api.BuildStrategy `json:"strategy,omitempty"`
+	// Timeout defines the Build maximum execution duration.
+	// The Build deadline is set to the Build start time plus the Timeout duration.
+	// If the Build deadline is exceeded, the Build context is canceled,
+	// and its phase set to BuildPhaseFailed. Defaults to 5 minutes.
+	// +kubebuilder:validation:Format=duration
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+	// SecurityProfile the security constraints applied to the Build execution. Defaults to `baseline`.
+	SecurityProfile api.SecurityProfile `json:"securityProfile,omitempty"`
+	// Job when set, the builder `Pod` is executed by a Kubernetes `Job` configured accordingly.
+	// +optional
+	Job *api.BuildJobSpec `json:"job,omitempty"`
+	// Resources reference to the object holding the build context, like the Dockerfile and the files it requires.
+	// The object must exist in the same namespace of the Build.
+	Resources *api.ResourceVolume `json:"resources,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Image",type=string,JSONPath=`.status.image`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// Build is the Schema for the builds API
+type Build struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BuildSpec       `json:"spec,omitempty"`
+	Status api.BuildStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BuildList contains a list of Build
+type BuildList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Build `json:"items"`
+}
+
+// ToBuild converts the Custom Resource to the environment-agnostic Build handled by the builder.
+// The Timeout defaults to defaults.BuildTimeout, since the builds without timeout fail as soon as they start.
+func (b *Build) ToBuild() *api.Build {
+	build := &api.Build{
+		ObjectReference: api.ObjectReference{
+			Namespace: b.Namespace,
+			Name:      b.Name,
+		},
+		Spec: api.BuildSpec{
+			Strategy:        b.Spec.Strategy,
+			Timeout:         b.Spec.Timeout,
+			SecurityProfile: b.Spec.SecurityProfile,
+		},
+		Status: *b.Status.DeepCopy(),
+	}
+	spec := b.Spec.DeepCopy()
+	build.Spec.Tasks = spec.Tasks
+	build.Spec.Job = spec.Job
+	if build.Spec.Timeout.Duration <= 0 {
+		build.Spec.Timeout = metav1.Duration{Duration: defaults.BuildTimeout}
+	}
+	if build.Status.ResourceVolume == nil && b.Spec.Resources != nil {
+		build.Status.ResourceVolume = b.Spec.Resources.DeepCopy()
+	}
+	return build
+}
+
+func init() {
+	SchemeBuilder.Register(&Build{}, &BuildList{})
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1alpha1
+
+import (
+	"github.com/kiegroup/container-builder/api"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+
+// PlatformBuild is the Schema for the platformbuilds API
+type PlatformBuild struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec api.PlatformBuildSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PlatformBuildList contains a list of PlatformBuild
+type PlatformBuildList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PlatformBuild `json:"items"`
+}
+
+// ToPlatformBuild converts the Custom Resource to the environment-agnostic PlatformBuild handled by the builder
+func (p *PlatformBuild) ToPlatformBuild() api.PlatformBuild {
+	return api.PlatformBuild{
+		ObjectReference: api.ObjectReference{
+			Namespace: p.Namespace,
+			Name:      p.Name,
+		},
+		Spec: *p.Spec.DeepCopy(),
+	}
+}
+
+func init() {
+	SchemeBuilder.Register(&PlatformBuild{}, &PlatformBuildList{})
+}
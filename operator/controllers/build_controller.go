@@ -0,0 +1,152 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package controllers provides a controller-runtime Reconciler managing the builds declared as Custom Resources.
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/kiegroup/container-builder/api"
+	builder "github.com/kiegroup/container-builder/builder/kubernetes"
+	"github.com/kiegroup/container-builder/client"
+	"github.com/kiegroup/container-builder/operator/api/v1alpha1"
+	"github.com/kiegroup/container-builder/util"
+	"github.com/kiegroup/container-builder/util/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// controllerName name of the controller, used as the source of its events
+const controllerName = "build-controller"
+
+// requeueAfter how often a running build is reconciled when no builder Pod events are received
+const requeueAfter = 10 * time.Second
+
+// BuildReconciler reconciles the Build Custom Resources, driving the builder and writing its status subresource.
+// The v1alpha1 types must be registered in the client scheme, see v1alpha1.AddToScheme.
+// The steps run by the Scheduler before the build starts are skipped, so the Build Custom Resources have no InputCache
+// nor PinBaseImages options, and the Kaniko version is only read from the executor image tag.
+type BuildReconciler struct {
+	Client client.Client
+	L      log.Logger
+	// Recorder emits the build events, attached to the Build. Optional.
+	Recorder record.EventRecorder
+	// TracerProvider provides the tracer of the build spans, the global one is used when it's nil. Optional.
+	TracerProvider trace.TracerProvider
+}
+
+// NewBuildReconciler creates a new BuildReconciler using the manager client.
+func NewBuildReconciler(mgr manager.Manager) (*BuildReconciler, error) {
+	c, err := client.FromManager(mgr)
+	if err != nil {
+		return nil, err
+	}
+	return &BuildReconciler{
+		Client:   c,
+		L:        log.WithName(util.ComponentName).WithName(controllerName),
+		Recorder: mgr.GetEventRecorderFor(controllerName),
+	}, nil
+}
+
+// +kubebuilder:rbac:groups=container-builder.kie.org,resources=builds,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=container-builder.kie.org,resources=builds/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=container-builder.kie.org,resources=platformbuilds,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods;configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile moves the Build forward, updating its status.
+func (r *BuildReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	build := &v1alpha1.Build{}
+	if err := r.Client.Get(ctx, req.NamespacedName, build); err != nil {
+		if k8serrors.IsNotFound(err) {
+			// the objects created by the builder are owned by the Build and garbage collected
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if build.Status.Phase.IsFinished() {
+		return ctrl.Result{}, nil
+	}
+
+	b := builder.FromBuild(build.ToBuild()).
+		WithClient(r.Client).
+		WithObjectDecorator(builder.OwnerReferenceDecorator(build, r.Client.GetScheme())).
+		WithTracerProvider(r.TracerProvider)
+	if r.Recorder != nil {
+		b.WithEventRecorder(r.Recorder, build)
+	}
+	target, err := b.Reconcile(ctx)
+	if err != nil {
+		r.L.Errorf(err, "Failed to reconcile build %s in namespace %s", build.Name, build.Namespace)
+		return ctrl.Result{}, err
+	}
+
+	if !equality.Semantic.DeepEqual(build.Status, target.Status) {
+		build.Status = target.Status
+		if err := r.Client.Status().Update(ctx, build); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if target.Status.Phase.IsFinished() {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// listBuilds lists the Builds of all the namespaces, counted by the queued builds metric
+func (r *BuildReconciler) listBuilds(ctx context.Context) ([]api.Build, error) {
+	list := &v1alpha1.BuildList{}
+	if err := r.Client.List(ctx, list); err != nil {
+		return nil, err
+	}
+	builds := make([]api.Build, 0, len(list.Items))
+	for i := range list.Items {
+		builds = append(builds, *list.Items[i].ToBuild())
+	}
+	return builds, nil
+}
+
+// SetupWithManager registers the controller, watching the Builds and the builder Pods and Jobs they own.
+// The builds metrics are exposed by the manager metrics endpoint.
+func (r *BuildReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := builder.RegisterMetrics(metrics.Registry); err != nil {
+		return err
+	}
+	if err := metrics.Registry.Register(builder.NewQueuedBuildsCollector(r.listBuilds)); err != nil {
+		if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+			return err
+		}
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.Build{}).
+		Owns(&corev1.Pod{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kiegroup/container-builder/api"
+	builder "github.com/kiegroup/container-builder/builder/kubernetes"
+	"github.com/kiegroup/container-builder/operator/api/v1alpha1"
+	"github.com/kiegroup/container-builder/util/defaults"
+	"github.com/kiegroup/container-builder/util/log"
+	"github.com/kiegroup/container-builder/util/test"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/yaml"
+)
+
+func TestBuildReconciler(t *testing.T) {
+	ns := "test"
+	assert.NoError(t, v1alpha1.AddToScheme(clientscheme.Scheme))
+
+	resources, build := newTestBuild(ns, "build1")
+	build.Spec.Timeout = metav1.Duration{Duration: 5 * time.Minute}
+	c, err := test.NewFakeClient(resources)
+	assert.NoError(t, err)
+	// the Kubernetes clientset doesn't know our types, so they're created only through the controller-runtime client
+	assert.NoError(t, c.Create(context.TODO(), build))
+
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &BuildReconciler{Client: c, L: log.Log, Recorder: recorder}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: build.Name, Namespace: ns}}
+	for _, phase := range []api.BuildPhase{api.BuildPhaseScheduling, api.BuildPhasePending, api.BuildPhasePending} {
+		result, err := reconciler.Reconcile(context.TODO(), request)
+		assert.NoError(t, err)
+		assert.Equal(t, requeueAfter, result.RequeueAfter)
+		assert.NoError(t, c.Get(context.TODO(), request.NamespacedName, build))
+		assert.Equal(t, phase, build.Status.Phase)
+	}
+
+	// the events are attached to the Build
+	assert.Equal(t, "Normal BuildPhaseChanged Build build1 moved from None to Scheduling", <-recorder.Events)
+	assert.Equal(t, "Normal BuildPhaseChanged Build build1 moved from Scheduling to Pending", <-recorder.Events)
+
+	pod := &v1.Pod{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Name: "kogito-build1-builder", Namespace: ns}, pod))
+	assert.Len(t, pod.OwnerReferences, 1)
+	assert.Equal(t, build.UID, pod.OwnerReferences[0].UID)
+	assert.Equal(t, resources.Name, pod.Spec.Volumes[0].ConfigMap.Name)
+
+	build.Status.Phase = api.BuildPhaseSucceeded
+	assert.NoError(t, c.Status().Update(context.TODO(), build))
+	result, err := reconciler.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+}
+
+func TestBuildReconcilerDefaultTimeout(t *testing.T) {
+	ns := "test"
+	assert.NoError(t, v1alpha1.AddToScheme(clientscheme.Scheme))
+
+	resources, build := newTestBuild(ns, "build2")
+	c, err := test.NewFakeClient(resources)
+	assert.NoError(t, err)
+	assert.NoError(t, c.Create(context.TODO(), build))
+
+	reconciler := &BuildReconciler{Client: c, L: log.Log}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: build.Name, Namespace: ns}}
+	for i := 0; i < 3; i++ {
+		_, err := reconciler.Reconcile(context.TODO(), request)
+		assert.NoError(t, err)
+	}
+
+	// the builder Pod isn't timed out as soon as it's monitored
+	pod := &v1.Pod{}
+	podName := types.NamespacedName{Name: "kogito-build2-builder", Namespace: ns}
+	assert.NoError(t, c.Get(context.TODO(), podName, pod))
+	pod.Status.Phase = v1.PodPending
+	assert.NoError(t, c.Status().Update(context.TODO(), pod))
+	_, err = reconciler.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.NoError(t, c.Get(context.TODO(), request.NamespacedName, build))
+	assert.Equal(t, api.BuildPhasePending, build.Status.Phase)
+	assert.NoError(t, c.Get(context.TODO(), podName, pod))
+	assert.NotContains(t, pod.Annotations, "kogito.kie.org/timeout")
+	assert.Equal(t, defaults.BuildTimeout, build.ToBuild().Spec.Timeout.Duration)
+}
+
+func TestBuildReconcilerQueuedBuilds(t *testing.T) {
+	ns := "test"
+	assert.NoError(t, v1alpha1.AddToScheme(clientscheme.Scheme))
+
+	resources, build := newTestBuild(ns, "build3")
+	c, err := test.NewFakeClient(resources)
+	assert.NoError(t, err)
+	assert.NoError(t, c.Create(context.TODO(), build))
+
+	reconciler := &BuildReconciler{Client: c, L: log.Log}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: build.Name, Namespace: ns}}
+	_, err = reconciler.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+
+	collector := builder.NewQueuedBuildsCollector(reconciler.listBuilds)
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector))
+
+	// the deleted builds leave the queue
+	assert.NoError(t, c.Delete(context.TODO(), build))
+	assert.Equal(t, 0, testutil.CollectAndCount(collector))
+}
+
+func TestBuildSchedulingOptions(t *testing.T) {
+	// the options applied when scheduling the builds aren't part of the Custom Resources, so that they're rejected
+	for _, option := range []string{"inputCache", "pinBaseImages"} {
+		build := &v1alpha1.Build{}
+		err := yaml.UnmarshalStrict([]byte("spec:\n  "+option+": true\n"), build)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown field "`+option+`"`)
+	}
+}
+
+// newTestBuild returns a Build without timeout and the ConfigMap holding its Dockerfile
+func newTestBuild(ns, name string) (*v1.ConfigMap, *v1alpha1.Build) {
+	resources := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name + "-context", Namespace: ns},
+		Data:       map[string]string{"Dockerfile": "FROM busybox"},
+	}
+	build := &v1alpha1.Build{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns, UID: types.UID(name + "-uid")},
+		Spec: v1alpha1.BuildSpec{
+			Strategy: api.BuildStrategyPod,
+			Tasks: []api.Task{{Kaniko: &api.KanikoTask{
+				BaseTask:    api.BaseTask{Name: "KanikoTask"},
+				PublishTask: api.PublishTask{ContextDir: "/builder/" + name + "/context", Image: "quay.io/kiegroup/buildexample:latest"},
+			}}},
+			Resources: &api.ResourceVolume{ReferenceName: resources.Name, ReferenceType: api.ResourceReferenceTypeConfigMap},
+		},
+	}
+	return resources, build
+}
+
+func TestBuildReconcilerExecutorImageEnv(t *testing.T) {
+	ns := "test"
+	assert.NoError(t, v1alpha1.AddToScheme(clientscheme.Scheme))
+	t.Setenv(defaults.KanikoExecutorImageEnv, "mirror.example.com/kaniko/executor:v1.9.0")
+
+	resources, build := newTestBuild(ns, "build3")
+	c, err := test.NewFakeClient(resources)
+	assert.NoError(t, err)
+	assert.NoError(t, c.Create(context.TODO(), build))
+
+	reconciler := &BuildReconciler{Client: c, L: log.Log}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: build.Name, Namespace: ns}}
+	for i := 0; i < 3; i++ {
+		_, err := reconciler.Reconcile(context.TODO(), request)
+		assert.NoError(t, err)
+	}
+
+	// the Builds without executor image are run by the one of the environment
+	pod := &v1.Pod{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Name: "kogito-build3-builder", Namespace: ns}, pod))
+	assert.Equal(t, "mirror.example.com/kaniko/executor:v1.9.0", pod.Spec.Containers[0].Image)
+}
@@ -16,8 +16,25 @@
 
 package defaults
 
+import "os"
+
 const (
 	KanikoVersion               = "1.9.0"
 	KanikoVersionSupportingKill = "0.17.1"
 	KanikoExecutorImage         = "gcr.io/kaniko-project/executor:v" + KanikoVersion
+	// KanikoExecutorImageEnv the environment variable setting the Kaniko executor image of the platforms which don't set any,
+	// like a mirror of KanikoExecutorImage in the air-gapped clusters
+	KanikoExecutorImageEnv = "KANIKO_EXECUTOR_IMAGE"
 )
+
+// GetKanikoExecutorImage returns the given Kaniko executor image, else the one of the KanikoExecutorImageEnv environment variable,
+// else KanikoExecutorImage
+func GetKanikoExecutorImage(image string) string {
+	if len(image) > 0 {
+		return image
+	}
+	if image := os.Getenv(KanikoExecutorImageEnv); len(image) > 0 {
+		return image
+	}
+	return KanikoExecutorImage
+}
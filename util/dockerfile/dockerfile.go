@@ -0,0 +1,106 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package dockerfile reads the base images of the Dockerfiles, shared by the builds on Kubernetes and the local ones
+package dockerfile
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// from a FROM instruction of a Dockerfile
+type from struct {
+	// line the index of the instruction line
+	line int
+	// image the image the stage starts from, or the name of a previous stage
+	image string
+	// stage the name given to the stage, if any
+	stage string
+}
+
+// froms returns the FROM instructions of the Dockerfile lines
+func froms(lines []string) []from {
+	var result []from
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+			continue
+		}
+		args := fields[1:]
+		for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			continue
+		}
+		f := from{line: i, image: args[0]}
+		if len(args) >= 3 && strings.EqualFold(args[1], "AS") {
+			f.stage = strings.ToLower(args[2])
+		}
+		result = append(result, f)
+	}
+	return result
+}
+
+// BaseImages returns the images the Dockerfile stages start from, skipping the previous stages and scratch.
+// An error is returned when the images depend on build arguments, so they can't be known before building.
+func BaseImages(dockerfile []byte) ([]string, error) {
+	var images []string
+	stages := map[string]bool{"scratch": true}
+	for _, f := range froms(strings.Split(string(dockerfile), "\n")) {
+		if !stages[strings.ToLower(f.image)] {
+			if strings.Contains(f.image, "$") {
+				return nil, errors.Errorf("base image %s depends on the build arguments", f.image)
+			}
+			images = append(images, f.image)
+		}
+		if len(f.stage) > 0 {
+			stages[f.stage] = true
+		}
+	}
+	return images, nil
+}
+
+// PinBaseImages rewrites the FROM instructions of the Dockerfile with the images returned by the pin function.
+// It returns the rewritten Dockerfile and the image of its final stage, empty if it starts from scratch.
+// The images depending on the build arguments are left as they are.
+func PinBaseImages(dockerfile []byte, pin func(image string) (string, error)) ([]byte, string, error) {
+	lines := strings.Split(string(dockerfile), "\n")
+	// the images of the previous stages, by name
+	stages := map[string]string{"scratch": ""}
+	final := ""
+	for _, f := range froms(lines) {
+		image, ok := stages[strings.ToLower(f.image)]
+		if !ok {
+			image = f.image
+			if !strings.Contains(image, "$") {
+				pinned, err := pin(image)
+				if err != nil {
+					return nil, "", err
+				}
+				lines[f.line] = strings.Replace(lines[f.line], image, pinned, 1)
+				image = pinned
+			}
+		}
+		if len(f.stage) > 0 {
+			stages[f.stage] = image
+		}
+		final = image
+	}
+	return []byte(strings.Join(lines, "\n")), final, nil
+}
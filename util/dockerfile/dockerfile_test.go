@@ -0,0 +1,50 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseImages(t *testing.T) {
+	images, err := BaseImages([]byte(`FROM --platform=linux/amd64 quay.io/kiegroup/kogito-swf-builder:latest AS builder
+FROM builder AS tests
+from scratch
+FROM registry.access.redhat.com/ubi8/openjdk-11:1.11
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"quay.io/kiegroup/kogito-swf-builder:latest", "registry.access.redhat.com/ubi8/openjdk-11:1.11"}, images)
+}
+
+func TestPinBaseImages(t *testing.T) {
+	content, final, err := PinBaseImages([]byte(`ARG VERSION
+FROM quay.io/kiegroup/kogito-swf-builder:latest AS builder
+FROM registry.access.redhat.com/ubi8/openjdk-11:${VERSION} AS runtime
+FROM builder
+`), func(image string) (string, error) {
+		return image + "@sha256:1234", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `ARG VERSION
+FROM quay.io/kiegroup/kogito-swf-builder:latest@sha256:1234 AS builder
+FROM registry.access.redhat.com/ubi8/openjdk-11:${VERSION} AS runtime
+FROM builder
+`, string(content))
+	assert.Equal(t, "quay.io/kiegroup/kogito-swf-builder:latest@sha256:1234", final)
+}
@@ -24,6 +24,11 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+const (
+	severityKey     = "severity"
+	severityWarning = "warning"
+)
+
 // Log --.
 var Log Logger
 
@@ -39,52 +44,109 @@ type Injectable interface {
 }
 
 // Logger --.
+// The secret values of the messages, errors and values are masked by its Redactor, the DefaultRedactor if none is set.
 type Logger struct {
 	delegate logr.Logger
+	redactor *Redactor
 }
 
 // Debugf --.
 func (l Logger) Debugf(format string, args ...interface{}) {
-	l.delegate.V(1).Info(fmt.Sprintf(format, args...))
+	l.delegate.V(1).Info(l.getRedactor().Redact(fmt.Sprintf(format, args...)))
 }
 
 // Infof --.
 func (l Logger) Infof(format string, args ...interface{}) {
-	l.delegate.Info(fmt.Sprintf(format, args...))
+	l.delegate.Info(l.getRedactor().Redact(fmt.Sprintf(format, args...)))
+}
+
+// Warnf --.
+func (l Logger) Warnf(format string, args ...interface{}) {
+	l.delegate.Info(l.getRedactor().Redact(fmt.Sprintf(format, args...)), severityKey, severityWarning)
 }
 
 // Errorf --.
 func (l Logger) Errorf(err error, format string, args ...interface{}) {
-	l.delegate.Error(err, fmt.Sprintf(format, args...))
+	r := l.getRedactor()
+	l.delegate.Error(r.RedactError(err), r.Redact(fmt.Sprintf(format, args...)))
 }
 
 // Debug --.
 func (l Logger) Debug(msg string, keysAndValues ...interface{}) {
-	l.delegate.V(1).Info(msg, keysAndValues...)
+	r := l.getRedactor()
+	l.delegate.V(1).Info(r.Redact(msg), redactKeysAndValues(r, keysAndValues)...)
 }
 
 // Info --.
 func (l Logger) Info(msg string, keysAndValues ...interface{}) {
-	l.delegate.Info(msg, keysAndValues...)
+	r := l.getRedactor()
+	l.delegate.Info(r.Redact(msg), redactKeysAndValues(r, keysAndValues)...)
+}
+
+// Warn --.
+// logr has no warning level, the warnings are logged with the info verbosity and tagged with the warning severity.
+func (l Logger) Warn(msg string, keysAndValues ...interface{}) {
+	r := l.getRedactor()
+	l.delegate.Info(r.Redact(msg), append([]interface{}{severityKey, severityWarning}, redactKeysAndValues(r, keysAndValues)...)...)
 }
 
 // Error --.
 func (l Logger) Error(err error, msg string, keysAndValues ...interface{}) {
-	l.delegate.Error(err, msg, keysAndValues...)
+	r := l.getRedactor()
+	l.delegate.Error(r.RedactError(err), r.Redact(msg), redactKeysAndValues(r, keysAndValues)...)
 }
 
 // WithName --.
 func (l Logger) WithName(name string) Logger {
 	return Logger{
 		delegate: l.delegate.WithName(name),
+		redactor: l.redactor,
 	}
 }
 
 // WithValues --.
 func (l Logger) WithValues(keysAndValues ...interface{}) Logger {
 	return Logger{
-		delegate: l.delegate.WithValues(keysAndValues...),
+		delegate: l.delegate.WithValues(redactKeysAndValues(l.getRedactor(), keysAndValues)...),
+		redactor: l.redactor,
+	}
+}
+
+// WithRedactor returns a copy of the Logger masking the secret values with the given Redactor
+func (l Logger) WithRedactor(redactor *Redactor) Logger {
+	return Logger{
+		delegate: l.delegate,
+		redactor: redactor,
+	}
+}
+
+func (l Logger) getRedactor() *Redactor {
+	if l.redactor == nil {
+		return DefaultRedactor()
+	}
+	return l.redactor
+}
+
+// redactKeysAndValues masks the values of the secret keys and the secret values of the strings and errors
+func redactKeysAndValues(r *Redactor, keysAndValues []interface{}) []interface{} {
+	redacted := make([]interface{}, len(keysAndValues))
+	for i, value := range keysAndValues {
+		if i%2 == 1 {
+			if key, ok := keysAndValues[i-1].(string); ok && r.IsSecretKey(key) {
+				redacted[i] = Redacted
+				continue
+			}
+		}
+		switch v := value.(type) {
+		case string:
+			redacted[i] = r.Redact(v)
+		case error:
+			redacted[i] = r.RedactError(v)
+		default:
+			redacted[i] = value
+		}
 	}
+	return redacted
 }
 
 // AsLogger --.
@@ -112,6 +174,11 @@ func Infof(format string, args ...interface{}) {
 	Log.Infof(format, args...)
 }
 
+// Warnf --.
+func Warnf(format string, args ...interface{}) {
+	Log.Warnf(format, args...)
+}
+
 // Errorf --.
 func Errorf(err error, format string, args ...interface{}) {
 	Log.Errorf(err, format, args...)
@@ -127,6 +194,11 @@ func Info(msg string, keysAndValues ...interface{}) {
 	Log.Info(msg, keysAndValues...)
 }
 
+// Warn --.
+func Warn(msg string, keysAndValues ...interface{}) {
+	Log.Warn(msg, keysAndValues...)
+}
+
 // Error --.
 func Error(err error, msg string, keysAndValues ...interface{}) {
 	Log.Error(err, msg, keysAndValues...)
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package mirrors pulls the images from the mirrors of their registry, shared by the builds on Kubernetes and the local ones
+package mirrors
+
+import (
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/kiegroup/container-builder/api"
+	"github.com/pkg/errors"
+)
+
+// dockerHubNames the names of the Docker Hub registry: the domain of the images without registry, like "busybox", the only
+// name known by Kaniko and the registry serving the images
+var dockerHubNames = []string{"docker.io", "index.docker.io", "registry-1.docker.io"}
+
+// IsDockerHub returns true if the given registry is the Docker Hub, the registry of the images without domain
+func IsDockerHub(registry string) bool {
+	for _, name := range dockerHubNames {
+		if registry == name {
+			return true
+		}
+	}
+	return false
+}
+
+// KanikoFlags returns the Kaniko flags pulling the images of the mirrored registries from their mirrors, tried in order
+// before the registry itself. The Docker Hub mirrors are set by --registry-mirror and the mirrors of the other registries by
+// --registry-map, the insecure mirrors being allowed by --insecure-registry.
+func KanikoFlags(mirrors []api.RegistryMirror) []string {
+	var flags, insecureFlags []string
+	insecure := map[string]bool{}
+	for _, mirror := range mirrors {
+		locations := make([]string, 0, len(mirror.Endpoints))
+		for _, endpoint := range mirror.Endpoints {
+			locations = append(locations, endpoint.Location)
+			if host := strings.SplitN(endpoint.Location, "/", 2)[0]; endpoint.Insecure && !insecure[host] {
+				insecure[host] = true
+				insecureFlags = append(insecureFlags, "--insecure-registry="+host)
+			}
+		}
+		if IsDockerHub(mirror.Registry) {
+			for _, location := range locations {
+				flags = append(flags, "--registry-mirror="+location)
+			}
+		} else if len(locations) > 0 {
+			flags = append(flags, "--registry-map="+mirror.Registry+"="+strings.Join(locations, ";"))
+		}
+	}
+	return append(flags, insecureFlags...)
+}
+
+// Image an image pulled from a mirror of its registry
+type Image struct {
+	// Name the image in the mirror, like "mirror.example.com/library/busybox:latest"
+	Name     string
+	Insecure bool
+}
+
+// Images returns the given image in every mirror of its registry, in the order they're tried before the registry itself.
+// The image is returned with the tag or the digest it's referenced with, "latest" by default.
+func Images(mirrors []api.RegistryMirror, image string) ([]Image, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid image %s", image)
+	}
+	named = reference.TagNameOnly(named)
+	suffix := ""
+	if digested, ok := named.(reference.Digested); ok {
+		suffix = "@" + digested.Digest().String()
+	} else if tagged, ok := named.(reference.Tagged); ok {
+		suffix = ":" + tagged.Tag()
+	}
+	domain := reference.Domain(named)
+	var images []Image
+	for _, mirror := range mirrors {
+		if mirror.Registry != domain && !(IsDockerHub(mirror.Registry) && IsDockerHub(domain)) {
+			continue
+		}
+		for _, endpoint := range mirror.Endpoints {
+			images = append(images, Image{
+				Name:     endpoint.Location + "/" + reference.Path(named) + suffix,
+				Insecure: endpoint.Insecure,
+			})
+		}
+	}
+	return images, nil
+}
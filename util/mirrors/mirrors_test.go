@@ -0,0 +1,58 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mirrors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiegroup/container-builder/api"
+)
+
+func TestImages(t *testing.T) {
+	mirrors := []api.RegistryMirror{
+		{Registry: "docker.io", Endpoints: []api.MirrorEndpoint{{Location: "cache.example.com:5000/docker-hub", Insecure: true}, {Location: "mirror.gcr.io"}}},
+		{Registry: "quay.io", Endpoints: []api.MirrorEndpoint{{Location: "cache.example.com:5000/quay"}, {Location: "quay-mirror.example.com"}}},
+	}
+	images, err := Images(mirrors, "busybox")
+	assert.NoError(t, err)
+	assert.Equal(t, []Image{
+		{Name: "cache.example.com:5000/docker-hub/library/busybox:latest", Insecure: true},
+		{Name: "mirror.gcr.io/library/busybox:latest"},
+	}, images)
+	images, err = Images(mirrors, "quay.io/kiegroup/kogito-swf-builder@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")
+	assert.NoError(t, err)
+	assert.Equal(t, []Image{
+		{Name: "cache.example.com:5000/quay/kiegroup/kogito-swf-builder@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+		{Name: "quay-mirror.example.com/kiegroup/kogito-swf-builder@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+	}, images)
+	images, err = Images(mirrors, "gcr.io/kaniko-project/executor:v1.9.1")
+	assert.NoError(t, err)
+	assert.Empty(t, images)
+}
+
+func TestKanikoFlags(t *testing.T) {
+	assert.Equal(t, []string{
+		"--registry-mirror=mirror.example.com",
+		"--registry-map=quay.io=cache.example.com:5000/quay;quay-mirror.example.com",
+		"--insecure-registry=cache.example.com:5000",
+	}, KanikoFlags([]api.RegistryMirror{
+		{Registry: "index.docker.io", Endpoints: []api.MirrorEndpoint{{Location: "mirror.example.com"}}},
+		{Registry: "quay.io", Endpoints: []api.MirrorEndpoint{{Location: "cache.example.com:5000/quay", Insecure: true}, {Location: "quay-mirror.example.com"}}},
+	}))
+}
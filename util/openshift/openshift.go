@@ -0,0 +1,66 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package openshift contains utilities for OpenShift deployments
+package openshift
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// UIDRangeAnnotation is set by OpenShift on every namespace with the range of UIDs allowed by the restricted SCCs.
+	UIDRangeAnnotation = "openshift.io/sa.scc.uid-range"
+)
+
+// IsOpenShift returns true if we are connected to an OpenShift cluster.
+// The cluster is discovered by every call, the clients cache the result, see client.Client.
+func IsOpenShift(client kubernetes.Interface) (bool, error) {
+	_, err := client.Discovery().ServerResourcesForGroupVersion("image.openshift.io/v1")
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return false, err
+	}
+	return err == nil, nil
+}
+
+// GetNamespaceUID returns the first UID of the range assigned by OpenShift to the given namespace, if any.
+func GetNamespaceUID(ctx context.Context, c ctrl.Client, namespace string) (*int64, error) {
+	ns := corev1.Namespace{}
+	if err := c.Get(ctx, types.NamespacedName{Name: namespace}, &ns); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	uidRange, ok := ns.Annotations[UIDRangeAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	// the range has the format <first UID>/<size>, e.g. 1000650000/10000
+	uid, err := strconv.ParseInt(strings.Split(uidRange, "/")[0], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &uid, nil
+}
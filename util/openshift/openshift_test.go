@@ -0,0 +1,37 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openshift
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestIsOpenShift(t *testing.T) {
+	result, err := IsOpenShift(fake.NewSimpleClientset())
+	assert.NoError(t, err)
+	assert.False(t, result)
+
+	openShift := fake.NewSimpleClientset()
+	openShift.Resources = []*metav1.APIResourceList{{GroupVersion: "image.openshift.io/v1"}}
+	result, err = IsOpenShift(openShift)
+	assert.NoError(t, err)
+	assert.True(t, result)
+}
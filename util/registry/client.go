@@ -0,0 +1,258 @@
+/*
+ * Copyright 2023 Red Hat, Inc. and/or its affiliates.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/distribution/reference"
+	registryclient "github.com/heroku/docker-registry-client/registry"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	// dockerHubDomain the domain of the images without registry, like "busybox"
+	dockerHubDomain = "docker.io"
+	// dockerHubRegistry the registry serving the Docker Hub images
+	dockerHubRegistry = "registry-1.docker.io"
+)
+
+// indexMediaTypes the media types of the manifests listing the images of every platform
+var indexMediaTypes = []string{manifestlist.MediaTypeManifestList, ocispec.MediaTypeImageIndex}
+
+// imageMediaTypes the media types of the manifests of a single image
+var imageMediaTypes = []string{schema2.MediaTypeManifest, ocispec.MediaTypeImageManifest}
+
+// Image describes an image found in a registry
+type Image struct {
+	// Digest the digest of the image manifest, or of the manifest list for multi-platform images
+	Digest digest.Digest
+	// Labels the labels of the image config, unknown for multi-platform images
+	Labels map[string]string
+}
+
+// Client looks up images in the registries, with the credentials of a Docker config.json if any.
+// Images are referenced as usual, like "quay.io/kiegroup/kogito-swf-builder:latest" or "busybox".
+type Client struct {
+	credentials map[string]credentials
+	insecure    map[string]bool
+
+	lock       sync.Mutex
+	registries map[string]*registryclient.Registry
+}
+
+type credentials struct {
+	username string
+	password string
+}
+
+// dockerConfig the subset of the Docker config.json holding the registries credentials
+type dockerConfig struct {
+	Auths map[string]struct {
+		Auth     string `json:"auth,omitempty"`
+		Username string `json:"username,omitempty"`
+		Password string `json:"password,omitempty"`
+	} `json:"auths"`
+}
+
+// NewClient returns a Client authenticated with the given Docker config.json, anonymous if empty.
+// The insecure registries, given by host and port, are contacted over HTTP.
+func NewClient(config []byte, insecureRegistries ...string) (*Client, error) {
+	c := &Client{
+		credentials: map[string]credentials{},
+		insecure:    map[string]bool{},
+		registries:  map[string]*registryclient.Registry{},
+	}
+	for _, host := range insecureRegistries {
+		c.insecure[registryHost(host)] = true
+	}
+	if len(config) == 0 {
+		return c, nil
+	}
+	parsed := dockerConfig{}
+	if err := json.Unmarshal(config, &parsed); err != nil {
+		return nil, errors.Wrap(err, "cannot parse the registry credentials")
+	}
+	for key, auth := range parsed.Auths {
+		creds := credentials{username: auth.Username, password: auth.Password}
+		if len(auth.Auth) > 0 {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot decode the credentials of registry %s", key)
+			}
+			username, password, found := strings.Cut(string(decoded), ":")
+			if !found {
+				return nil, errors.Errorf("invalid credentials of registry %s", key)
+			}
+			creds = credentials{username: username, password: password}
+		}
+		c.credentials[registryHost(key)] = creds
+	}
+	return c, nil
+}
+
+// Digest returns the digest of the image, or an empty digest if the image doesn't exist.
+// The digest of the manifest list is returned for multi-platform images.
+func (c *Client) Digest(ctx context.Context, image string) (digest.Digest, error) {
+	registry, repository, ref, err := c.parse(image)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.request(ctx, registry, http.MethodHead, fmt.Sprintf("/v2/%s/manifests/%s", repository, ref), append(indexMediaTypes, imageMediaTypes...))
+	if err != nil {
+		if isNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "cannot get the digest of image %s", image)
+	}
+	resp.Body.Close()
+	if d, err := digest.Parse(resp.Header.Get("Docker-Content-Digest")); err == nil {
+		return d, nil
+	}
+	// some registries don't tell the digest upon HEAD requests
+	found, err := c.Image(ctx, image)
+	if err != nil || found == nil {
+		return "", err
+	}
+	return found.Digest, nil
+}
+
+// Image returns the image digest and labels, or nil if the image doesn't exist
+func (c *Client) Image(ctx context.Context, image string) (*Image, error) {
+	registry, repository, ref, err := c.parse(image)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.request(ctx, registry, http.MethodGet, fmt.Sprintf("/v2/%s/manifests/%s", repository, ref), append(indexMediaTypes, imageMediaTypes...))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "cannot get the manifest of image %s", image)
+	}
+	defer resp.Body.Close()
+	manifest, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read the manifest of image %s", image)
+	}
+	found := &Image{Digest: digest.FromBytes(manifest)}
+
+	mediaType, _, _ := strings.Cut(resp.Header.Get("Content-Type"), ";")
+	for _, indexType := range indexMediaTypes {
+		if mediaType == indexType {
+			return found, nil
+		}
+	}
+	parsed := ocispec.Manifest{}
+	if err := json.Unmarshal(manifest, &parsed); err != nil {
+		return nil, errors.Wrapf(err, "cannot parse the manifest of image %s", image)
+	}
+	if len(parsed.Config.Digest) == 0 {
+		// schema 1 manifests have no config
+		return found, nil
+	}
+	resp, err = c.request(ctx, registry, http.MethodGet, fmt.Sprintf("/v2/%s/blobs/%s", repository, parsed.Config.Digest), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get the config of image %s", image)
+	}
+	defer resp.Body.Close()
+	config := ocispec.Image{}
+	if err := json.NewDecoder(resp.Body).Decode(&config); err != nil {
+		return nil, errors.Wrapf(err, "cannot parse the config of image %s", image)
+	}
+	found.Labels = config.Config.Labels
+	return found, nil
+}
+
+// parse returns the client of the image registry, the image repository and its tag or digest
+func (c *Client) parse(image string) (*registryclient.Registry, string, string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return nil, "", "", errors.Wrapf(err, "invalid image %s", image)
+	}
+	ref := "latest"
+	if digested, ok := named.(reference.Digested); ok {
+		ref = digested.Digest().String()
+	} else if tagged, ok := named.(reference.Tagged); ok {
+		ref = tagged.Tag()
+	}
+	return c.registry(reference.Domain(named)), reference.Path(named), ref, nil
+}
+
+// registry returns the client of the given registry, authenticated with its credentials if any
+func (c *Client) registry(domain string) *registryclient.Registry {
+	host := registryHost(domain)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if registry, ok := c.registries[host]; ok {
+		return registry
+	}
+	address := host
+	if host == dockerHubDomain {
+		address = dockerHubRegistry
+	}
+	url := "https://" + address
+	if c.insecure[host] {
+		url = "http://" + address
+	}
+	creds := c.credentials[host]
+	registry := &registryclient.Registry{
+		URL:    url,
+		Client: &http.Client{Transport: registryclient.WrapTransport(http.DefaultTransport, url, creds.username, creds.password)},
+		Logf:   registryclient.Quiet,
+	}
+	c.registries[host] = registry
+	return registry
+}
+
+func (c *Client) request(ctx context.Context, registry *registryclient.Registry, method, path string, accept []string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, registry.URL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(accept) > 0 {
+		req.Header.Set("Accept", strings.Join(accept, ", "))
+	}
+	return registry.Client.Do(req)
+}
+
+// registryHost returns the host of a registry address or of a Docker config key, like "https://index.docker.io/v1/"
+func registryHost(address string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(address, "https://"), "http://")
+	host, _, _ = strings.Cut(host, "/")
+	switch host {
+	case "index.docker.io", dockerHubRegistry:
+		return dockerHubDomain
+	}
+	return host
+}
+
+func isNotFound(err error) bool {
+	var statusErr *registryclient.HTTPStatusError
+	return errors.As(err, &statusErr) && statusErr.Response.StatusCode == http.StatusNotFound
+}
@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	"github.com/kiegroup/container-builder/client"
+	"github.com/kiegroup/container-builder/util/openshift"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -122,6 +123,10 @@ func (c *FakeClient) GetCurrentNamespace(kubeConfig string) (string, error) {
 	return "", nil
 }
 
+func (c *FakeClient) IsOpenShift() (bool, error) {
+	return openshift.IsOpenShift(c)
+}
+
 // Patch mimicks patch for server-side apply and simply creates the obj.
 func (c *FakeClient) Patch(ctx context.Context, obj controller.Object, patch controller.Patch, opts ...controller.PatchOption) error {
 	return c.Create(ctx, obj)